@@ -0,0 +1,270 @@
+// Package autosplit alternates the direction new windows are split in, which bspwm's automatic scheme doesn't do.
+//
+// Before each window is inserted, the focused window of the desktop is preselected, in a direction chosen
+// by the desktop's mode. Preselections made by hand are left alone, and the ones made here are canceled
+// as soon as the focus moves away, so that they don't linger on windows nobody is looking at anymore.
+// Example usage:
+//
+//	s := autosplit.NewSplitter(c, nil)
+//	err := s.SetDefaultMode(autosplit.ModeSpiral)
+//	err = s.Run(ctx)
+package autosplit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/diogox/bspc-go"
+)
+
+// Mode decides the direction the focused window of a desktop is preselected in.
+type Mode string
+
+const (
+	// ModeNone leaves the desktop to bspwm's automatic scheme.
+	ModeNone Mode = ""
+	// ModeSpiral turns clockwise with each window: east, south, west, then north, as in a fibonacci spiral.
+	ModeSpiral Mode = "spiral"
+	// ModeDwindle alternates between east and south, so that windows shrink towards the bottom right corner.
+	ModeDwindle Mode = "dwindle"
+	// ModeLongestSide splits the focused window along its longest side.
+	ModeLongestSide Mode = "longest-side"
+)
+
+// IsValid returns true if the mode is ModeNone, or one of the modes Direction knows.
+func (m Mode) IsValid() bool {
+	return m == ModeNone ||
+		m == ModeSpiral ||
+		m == ModeDwindle ||
+		m == ModeLongestSide
+}
+
+// Direction returns the direction to preselect the leaf in, in the tree rooted at the node.
+func (m Mode) Direction(root *bspc.Node, leaf *bspc.Node) bspc.DirectionType {
+	switch m {
+	case ModeSpiral:
+		dirs := []bspc.DirectionType{bspc.DirectionTypeRight, bspc.DirectionTypeDown, bspc.DirectionTypeLeft, bspc.DirectionTypeUp}
+		return dirs[depth(root, leaf)%len(dirs)]
+	case ModeDwindle:
+		dirs := []bspc.DirectionType{bspc.DirectionTypeRight, bspc.DirectionTypeDown}
+		return dirs[depth(root, leaf)%len(dirs)]
+	case ModeLongestSide:
+		if leaf.Rectangle.Width >= leaf.Rectangle.Height {
+			return bspc.DirectionTypeRight
+		}
+
+		return bspc.DirectionTypeDown
+	}
+
+	return ""
+}
+
+func depth(root *bspc.Node, leaf *bspc.Node) int {
+	if d := root.Depth(leaf.ID); d > 0 {
+		return d
+	}
+
+	return 0
+}
+
+// splitEvents are the events a Splitter follows, to preselect the focused windows and cancel its stale preselections.
+var splitEvents = []bspc.EventType{
+	bspc.EventTypeNodeAdd,
+	bspc.EventTypeNodeFocus,
+	bspc.EventTypeNodePreselect,
+	bspc.EventTypeNodeRemove,
+}
+
+type (
+	// Splitter preselects the focused window of each desktop with a mode, as the focus moves and windows are added.
+	Splitter struct {
+		client bspc.Client
+		logger bspc.Logger
+
+		mu          sync.Mutex
+		modes       map[bspc.ID]Mode
+		defaultMode Mode
+		// presels are the preselections made by the splitter, by desktop. Each desktop has one at most.
+		presels map[bspc.ID]presel
+	}
+
+	presel struct {
+		nodeID bspc.ID
+		dir    bspc.DirectionType
+	}
+)
+
+// NewSplitter returns a splitter for the bspwm instance behind the client. Desktops have no mode until one is set.
+// If the value passed in as a logger is nil, logging will be disabled.
+func NewSplitter(c bspc.Client, logger bspc.Logger) *Splitter {
+	return &Splitter{
+		client:  c,
+		logger:  logger,
+		modes:   make(map[bspc.ID]Mode),
+		presels: make(map[bspc.ID]presel),
+	}
+}
+
+// SetDefaultMode sets the mode of the desktops that weren't given one.
+// An invalid mode is refused, with an error matching bspc.ErrInvalidArgument.
+func (s *Splitter) SetDefaultMode(mode Mode) error {
+	if !mode.IsValid() {
+		return fmt.Errorf("%w: invalid mode %s", bspc.ErrInvalidArgument, mode)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.defaultMode = mode
+
+	return nil
+}
+
+// SetMode sets the mode of the desktop. ModeNone leaves it alone, instead of using the default mode.
+// It doesn't preselect the desktop's focused window, call Refresh for that.
+// An invalid mode is refused, with an error matching bspc.ErrInvalidArgument.
+func (s *Splitter) SetMode(desktopID bspc.ID, mode Mode) error {
+	if !mode.IsValid() {
+		return fmt.Errorf("%w: invalid mode %s", bspc.ErrInvalidArgument, mode)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.modes[desktopID] = mode
+
+	return nil
+}
+
+// Mode returns the mode of the desktop.
+func (s *Splitter) Mode(desktopID bspc.ID) Mode {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if mode, ok := s.modes[desktopID]; ok {
+		return mode
+	}
+
+	return s.defaultMode
+}
+
+// Refresh preselects the focused window of the desktop, according to its mode, unless it was preselected by hand.
+// The splitter's preselection of any other window of the desktop is canceled.
+func (s *Splitter) Refresh(ctx context.Context, desktopID bspc.ID) error {
+	mode := s.Mode(desktopID)
+
+	d, err := s.client.DesktopTreeContext(ctx, bspc.ByID(desktopID))
+	if err != nil {
+		return fmt.Errorf("failed to query desktop: %w", err)
+	}
+
+	var focused *bspc.Node
+	if mode != ModeNone && d.FocusedNodeID != bspc.NilID {
+		if n := d.FindNode(d.FocusedNodeID); n != nil && n.IsLeaf() {
+			focused = n
+		}
+	}
+
+	s.mu.Lock()
+	stale, ok := s.presels[desktopID]
+	s.mu.Unlock()
+
+	if ok && (focused == nil || stale.nodeID != focused.ID) {
+		if err := s.cancel(ctx, d, desktopID, stale); err != nil {
+			return err
+		}
+	}
+
+	if focused == nil {
+		return nil
+	}
+
+	if p := focused.Preselect; p != nil {
+		// Either it's already the splitter's, or it was made by hand.
+		return nil
+	}
+
+	dir := mode.Direction(&d.Root, focused)
+
+	// It's tracked before it's made, so that its event is recognized.
+	s.mu.Lock()
+	s.presels[desktopID] = presel{nodeID: focused.ID, dir: dir}
+	s.mu.Unlock()
+
+	return s.client.Node(bspc.ByID(focused.ID)).WithContext(ctx).Presel(dir)
+}
+
+// cancel cancels the splitter's preselection, if the node is still preselected the way it left it.
+func (s *Splitter) cancel(ctx context.Context, d bspc.Desktop, desktopID bspc.ID, p presel) error {
+	s.mu.Lock()
+	delete(s.presels, desktopID)
+	s.mu.Unlock()
+
+	n := d.FindNode(p.nodeID)
+	if n == nil || n.Preselect == nil || n.Preselect.SplitDirection != p.dir {
+		return nil
+	}
+
+	return s.client.Node(bspc.ByID(p.nodeID)).WithContext(ctx).PreselCancel()
+}
+
+// Run subscribes to bspwm's events, and preselects the focused windows of the desktops with a mode, until the
+// context is done or the subscription fails. It blocks, and always returns a non-nil error.
+// Failing to preselect a window is only logged, as windows can go away in the meantime.
+func (s *Splitter) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	eventCh, errCh, err := s.client.SubscribeEventsContext(ctx, splitEvents[0], splitEvents[1:]...)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to events: %w", err)
+	}
+
+	return bspc.ConsumeEvents(ctx, eventCh, errCh, s.logger, func(ev bspc.Event) error {
+		desktopID, refresh := s.apply(ev)
+		if !refresh {
+			return nil
+		}
+
+		if err := s.Refresh(ctx, desktopID); err != nil {
+			return fmt.Errorf("failed to preselect: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// apply forgets the preselections the event consumed or took over, and returns the desktop to refresh, if any.
+func (s *Splitter) apply(ev bspc.Event) (bspc.ID, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch p := ev.Payload.(type) {
+	case bspc.EventNodeAdd:
+		// The new window was inserted at the preselected node.
+		if own, ok := s.presels[p.DesktopID]; ok && own.nodeID == p.IPID {
+			delete(s.presels, p.DesktopID)
+		}
+
+		return p.DesktopID, true
+	case bspc.EventNodeFocus:
+		return p.DesktopID, true
+	case bspc.EventNodePreselect:
+		own, ok := s.presels[p.DesktopID]
+		if !ok || own.nodeID != p.NodeID {
+			return bspc.NilID, false
+		}
+
+		// Changed by hand, so it isn't the splitter's anymore.
+		if p.IsCancel != nil || (p.SplitDirection != nil && *p.SplitDirection != own.dir) {
+			delete(s.presels, p.DesktopID)
+		}
+	case bspc.EventNodeRemove:
+		if own, ok := s.presels[p.DesktopID]; ok && own.nodeID == p.NodeID {
+			delete(s.presels, p.DesktopID)
+		}
+	}
+
+	return bspc.NilID, false
+}
@@ -0,0 +1,203 @@
+package autosplit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diogox/bspc-go"
+	"github.com/diogox/bspc-go/autosplit"
+	"github.com/diogox/bspc-go/bspctest"
+)
+
+const (
+	desktopID = bspc.ID(0x00200003)
+	rootID    = bspc.ID(0x00400001)
+	parentID  = bspc.ID(0x00400002)
+	aID       = bspc.ID(0x00E00003)
+	bID       = bspc.ID(0x00E00004)
+	cID       = bspc.ID(0x00E00005)
+
+	desktopQuery = "query --tree --desktop 0x00200003"
+)
+
+func window(id bspc.ID) *bspc.Node {
+	return &bspc.Node{ID: id, Client: &bspc.NodeClient{State: bspc.StateTypeTiled}}
+}
+
+// testDesktop holds: a | (b / c)
+func testDesktop(focusedID bspc.ID) bspc.Desktop {
+	return bspc.Desktop{
+		ID:            desktopID,
+		FocusedNodeID: focusedID,
+		Root: bspc.Node{
+			ID:          rootID,
+			SplitType:   bspc.SplitTypeVertical,
+			SplitRatio:  0.5,
+			FirstChild:  window(aID),
+			SecondChild: &bspc.Node{ID: parentID, SplitType: bspc.SplitTypeHorizontal, SplitRatio: 0.5, FirstChild: window(bID), SecondChild: window(cID)},
+		},
+	}
+}
+
+func TestMode_Direction(t *testing.T) {
+	d := testDesktop(aID)
+	root := &d.Root
+	c := root.SecondChild.SecondChild
+
+	wide := &bspc.Node{ID: aID, Rectangle: bspc.Rectangle{Width: 800, Height: 600}}
+	tall := &bspc.Node{ID: aID, Rectangle: bspc.Rectangle{Width: 600, Height: 800}}
+
+	tests := []struct {
+		name string
+		mode autosplit.Mode
+		root *bspc.Node
+		leaf *bspc.Node
+		want bspc.DirectionType
+	}{
+		{name: "should split the root east, in spiral", mode: autosplit.ModeSpiral, root: wide, leaf: wide, want: bspc.DirectionTypeRight},
+		{name: "should turn clockwise with the depth, in spiral", mode: autosplit.ModeSpiral, root: root, leaf: c, want: bspc.DirectionTypeLeft},
+		{name: "should alternate with the depth, in dwindle", mode: autosplit.ModeDwindle, root: root, leaf: c, want: bspc.DirectionTypeRight},
+		{name: "should split wide windows east, in longest-side", mode: autosplit.ModeLongestSide, root: wide, leaf: wide, want: bspc.DirectionTypeRight},
+		{name: "should split tall windows south, in longest-side", mode: autosplit.ModeLongestSide, root: tall, leaf: tall, want: bspc.DirectionTypeDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.mode.Direction(tt.root, tt.leaf))
+		})
+	}
+}
+
+func TestSplitter_SetMode(t *testing.T) {
+	t.Run("should refuse modes it doesn't know", func(t *testing.T) {
+		c, _ := bspctest.NewClient(t)
+		s := autosplit.NewSplitter(c, nil)
+
+		assert.ErrorIs(t, s.SetMode(desktopID, autosplit.Mode("tiled")), bspc.ErrInvalidArgument)
+		assert.ErrorIs(t, s.SetDefaultMode(autosplit.Mode("tiled")), bspc.ErrInvalidArgument)
+		assert.Equal(t, autosplit.ModeNone, s.Mode(desktopID))
+	})
+}
+
+func TestSplitter_Refresh(t *testing.T) {
+	t.Run("should preselect the focused window, according to the desktop's mode", func(t *testing.T) {
+		c, srv := bspctest.NewClient(t)
+		srv.RespondJSON(desktopQuery, testDesktop(bID))
+
+		s := autosplit.NewSplitter(c, nil)
+		require.NoError(t, s.SetMode(desktopID, autosplit.ModeSpiral))
+
+		require.NoError(t, s.Refresh(context.Background(), desktopID))
+		assert.Equal(t, []string{desktopQuery, "node 0x00E00004 --presel-dir west"}, srv.Commands())
+	})
+
+	t.Run("should leave preselections made by hand alone", func(t *testing.T) {
+		c, srv := bspctest.NewClient(t)
+
+		d := testDesktop(bID)
+		d.Root.SecondChild.FirstChild.Preselect = &bspc.NodePreselect{SplitDirection: bspc.DirectionTypeUp, SplitRatio: 0.5}
+		srv.RespondJSON(desktopQuery, d)
+
+		s := autosplit.NewSplitter(c, nil)
+		require.NoError(t, s.SetDefaultMode(autosplit.ModeDwindle))
+
+		require.NoError(t, s.Refresh(context.Background(), desktopID))
+		assert.Equal(t, []string{desktopQuery}, srv.Commands())
+	})
+
+	t.Run("should cancel its preselection once the focus moves away", func(t *testing.T) {
+		c, srv := bspctest.NewClient(t)
+		srv.RespondJSON(desktopQuery, testDesktop(aID))
+
+		s := autosplit.NewSplitter(c, nil)
+		require.NoError(t, s.SetMode(desktopID, autosplit.ModeDwindle))
+		require.NoError(t, s.Refresh(context.Background(), desktopID))
+
+		d := testDesktop(cID)
+		d.Root.FirstChild.Preselect = &bspc.NodePreselect{SplitDirection: bspc.DirectionTypeDown, SplitRatio: 0.5}
+		srv.RespondJSON(desktopQuery, d)
+		require.NoError(t, s.Refresh(context.Background(), desktopID))
+
+		assert.Equal(t, []string{
+			desktopQuery,
+			"node 0x00E00003 --presel-dir south",
+			desktopQuery,
+			"node 0x00E00003 --presel-dir cancel",
+			"node 0x00E00005 --presel-dir east",
+		}, srv.Commands())
+	})
+
+	t.Run("should cancel its preselection once the desktop has no mode", func(t *testing.T) {
+		c, srv := bspctest.NewClient(t)
+		srv.RespondJSON(desktopQuery, testDesktop(aID))
+
+		s := autosplit.NewSplitter(c, nil)
+		require.NoError(t, s.SetMode(desktopID, autosplit.ModeDwindle))
+		require.NoError(t, s.Refresh(context.Background(), desktopID))
+
+		d := testDesktop(aID)
+		d.Root.FirstChild.Preselect = &bspc.NodePreselect{SplitDirection: bspc.DirectionTypeDown, SplitRatio: 0.5}
+		srv.RespondJSON(desktopQuery, d)
+
+		require.NoError(t, s.SetMode(desktopID, autosplit.ModeNone))
+		require.NoError(t, s.Refresh(context.Background(), desktopID))
+
+		assert.Equal(t, "node 0x00E00003 --presel-dir cancel", srv.Commands()[len(srv.Commands())-1])
+	})
+}
+
+func TestSplitter_Run(t *testing.T) {
+	t.Run("should not cancel preselections that were changed by hand", func(t *testing.T) {
+		c, srv := bspctest.NewClient(t)
+		srv.RespondJSON(desktopQuery, testDesktop(aID))
+
+		s := autosplit.NewSplitter(c, nil)
+		require.NoError(t, s.SetMode(desktopID, autosplit.ModeDwindle))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- s.Run(ctx)
+		}()
+
+		srv.WaitForSubscribers(1)
+		srv.Publish("node_focus 0x00200002 0x00200003 0x00E00003")
+
+		waitForCommand(t, srv, "node 0x00E00003 --presel-dir south")
+
+		d := testDesktop(cID)
+		d.Root.FirstChild.Preselect = &bspc.NodePreselect{SplitDirection: bspc.DirectionTypeUp, SplitRatio: 0.5}
+		srv.RespondJSON(desktopQuery, d)
+
+		srv.Publish(
+			"node_presel 0x00200002 0x00200003 0x00E00003 dir north",
+			"node_focus 0x00200002 0x00200003 0x00E00005",
+		)
+
+		waitForCommand(t, srv, "node 0x00E00005 --presel-dir east")
+		assert.NotContains(t, srv.Commands(), "node 0x00E00003 --presel-dir cancel")
+
+		cancel()
+		assert.ErrorIs(t, <-errCh, context.Canceled)
+	})
+}
+
+func waitForCommand(t *testing.T, srv *bspctest.Server, cmd string) {
+	t.Helper()
+
+	require.Eventually(t, func() bool {
+		for _, c := range srv.Commands() {
+			if c == cmd {
+				return true
+			}
+		}
+
+		return false
+	}, time.Second, 10*time.Millisecond)
+}
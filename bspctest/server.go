@@ -0,0 +1,335 @@
+package bspctest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/diogox/bspc-go"
+)
+
+// This is a fake bspwm, for testing code that talks to it through a real socket.
+// Example usage:
+//
+// srv := bspctest.NewServer(t)
+// srv.Respond("query -N -n focused", "0x00E00003\n")
+// srv.Fail("node -f nonsense", "node: Invalid descriptor found in 'nonsense'.")
+//
+// c, err := bspc.NewWithSocketPath(srv.SocketPath(), nil)
+//
+// Or, for a client that's connected to a fresh server:
+//
+// c, srv := bspctest.NewClient(t)
+//
+// Commands are matched against their words joined by spaces. Commands that weren't
+// scripted succeed with an empty response.
+
+const (
+	// failureMessage is the byte bspwm prefixes its response with, when a command fails.
+	failureMessage = "\a"
+
+	// maxMessageSize mirrors the size of the buffer bspwm reads messages into.
+	maxMessageSize = 8192
+
+	waitTimeout = 5 * time.Second
+)
+
+type (
+	Server struct {
+		t        *testing.T
+		dir      string
+		listener *net.UnixListener
+
+		mu          sync.Mutex
+		responses   map[string]response
+		commands    []string
+		subscribers []*subscriber
+		conns       map[net.Conn]struct{}
+		closed      bool
+
+		// hang is closed along with the server, to release the connections of hanging commands.
+		hang chan struct{}
+		wg   sync.WaitGroup
+	}
+
+	response struct {
+		payload string
+		failed  bool
+		hangs   bool
+		fn      func() string
+	}
+
+	subscriber struct {
+		conn   net.Conn
+		events []string
+	}
+)
+
+// NewServer starts a fake bspwm, listening on a unix socket in a temporary directory.
+// It is closed automatically when the test finishes.
+func NewServer(t *testing.T) *Server {
+	// Unix socket paths are limited to around a hundred bytes, which the directories
+	// created by t.TempDir can exceed.
+	dir, err := ioutil.TempDir("", "bspctest")
+	require.NoError(t, err)
+
+	addr, err := net.ResolveUnixAddr("unix", filepath.Join(dir, "bspwm-socket"))
+	require.NoError(t, err)
+
+	listener, err := net.ListenUnix("unix", addr)
+	require.NoError(t, err)
+
+	s := &Server{
+		t:         t,
+		dir:       dir,
+		listener:  listener,
+		responses: make(map[string]response),
+		conns:     make(map[net.Conn]struct{}),
+		hang:      make(chan struct{}),
+	}
+
+	s.wg.Add(1)
+	go s.accept()
+
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// NewClient starts a fake bspwm, as NewServer does, and returns a client connected to it.
+func NewClient(t *testing.T) (bspc.Client, *Server) {
+	s := NewServer(t)
+
+	c, err := bspc.NewWithSocketPath(s.SocketPath(), nil)
+	require.NoError(t, err)
+
+	return c, s
+}
+
+// SocketPath returns the path of the server's socket, to be passed into bspc.NewWithSocketPath.
+func (s *Server) SocketPath() string {
+	return s.listener.Addr().String()
+}
+
+// Respond scripts the response to the command.
+func (s *Server) Respond(cmd string, payload string) {
+	s.script(cmd, response{payload: payload})
+}
+
+// RespondJSON scripts the response to the command, as the JSON encoding of the value.
+func (s *Server) RespondJSON(cmd string, v interface{}) {
+	bb, err := json.Marshal(v)
+	require.NoError(s.t, err)
+
+	s.Respond(cmd, string(bb))
+}
+
+// RespondFunc scripts the response to the command, as the result of calling fn each time it is received.
+// It is meant for commands whose responses change along the test, such as queries.
+func (s *Server) RespondFunc(cmd string, fn func() string) {
+	s.script(cmd, response{fn: fn})
+}
+
+// Fail scripts the command to fail, with the given error message.
+func (s *Server) Fail(cmd string, msg string) {
+	s.script(cmd, response{payload: msg, failed: true})
+}
+
+// Hang scripts the command to never be responded to, until the server is closed.
+func (s *Server) Hang(cmd string) {
+	s.script(cmd, response{hangs: true})
+}
+
+// Commands returns every command received so far, subscriptions included, in order.
+func (s *Server) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.commands...)
+}
+
+// WaitForSubscribers blocks until at least n subscriptions are active, and fails the test
+// if that doesn't happen in a few seconds.
+func (s *Server) WaitForSubscribers(n int) {
+	require.Eventually(s.t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		return len(s.subscribers) >= n
+	}, waitTimeout, time.Millisecond)
+}
+
+// Publish sends the events, newline-terminated and in a single write, to every subscriber
+// that subscribed to them. Reports are recognized by their "W" prefix.
+func (s *Server) Publish(events ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subscribers := s.subscribers[:0]
+	for _, sub := range s.subscribers {
+		var payload string
+		for _, ev := range events {
+			if sub.wants(ev) {
+				payload += ev + "\n"
+			}
+		}
+
+		// Subscribers that went away are dropped, as bspwm does.
+		if payload != "" {
+			if _, err := sub.conn.Write([]byte(payload)); err != nil {
+				_ = sub.conn.Close()
+				continue
+			}
+		}
+
+		subscribers = append(subscribers, sub)
+	}
+
+	s.subscribers = subscribers
+}
+
+// DisconnectSubscribers closes the connection of every active subscription, as bspwm does when it restarts.
+func (s *Server) DisconnectSubscribers() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, sub := range s.subscribers {
+		_ = sub.conn.Close()
+	}
+
+	s.subscribers = nil
+}
+
+// Close stops the server and closes every connection.
+func (s *Server) Close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+
+	s.closed = true
+	close(s.hang)
+
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.mu.Unlock()
+
+	_ = s.listener.Close()
+	s.wg.Wait()
+
+	_ = os.RemoveAll(s.dir)
+}
+
+func (s *Server) script(cmd string, res response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.responses[cmd] = res
+}
+
+func (s *Server) accept() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			_ = conn.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go s.handle(conn)
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer s.wg.Done()
+
+	closeConn := true
+	defer func() {
+		if closeConn {
+			s.forget(conn)
+		}
+	}()
+
+	buffer := make([]byte, maxMessageSize)
+	n, err := conn.Read(buffer)
+	if err != nil {
+		return
+	}
+
+	words := strings.Split(strings.TrimSuffix(string(buffer[:n]), "\x00"), "\x00")
+	cmd := strings.Join(words, " ")
+
+	s.mu.Lock()
+	s.commands = append(s.commands, cmd)
+	res := s.responses[cmd]
+
+	if words[0] == "subscribe" && !res.failed {
+		s.subscribers = append(s.subscribers, &subscriber{conn: conn, events: words[1:]})
+		s.mu.Unlock()
+
+		// The connection stays open, for the published events.
+		closeConn = false
+		return
+	}
+	s.mu.Unlock()
+
+	if res.hangs {
+		<-s.hang
+		return
+	}
+
+	payload := res.payload
+	if res.fn != nil {
+		payload = res.fn()
+	}
+
+	if res.failed {
+		payload = failureMessage + payload
+	}
+
+	_, _ = conn.Write([]byte(payload))
+}
+
+func (s *Server) forget(conn net.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.conns, conn)
+	_ = conn.Close()
+}
+
+// wants returns true if the subscriber subscribed to the event.
+func (sub *subscriber) wants(event string) bool {
+	name := strings.SplitN(event, " ", 2)[0]
+	if strings.HasPrefix(event, "W") {
+		name = "report"
+	}
+
+	for _, ev := range sub.events {
+		if ev == name || ev == "all" {
+			return true
+		}
+	}
+
+	return false
+}
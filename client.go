@@ -1,11 +1,8 @@
 package bspc
 
 import (
-	"errors"
+	"context"
 	"fmt"
-	"os"
-	"path/filepath"
-	"regexp"
 	"strconv"
 	"strings"
 )
@@ -18,8 +15,40 @@ type (
 
 	Client interface {
 		Query(rawCmd string, resResolver QueryResponseResolver) error
+		QueryContext(ctx context.Context, rawCmd string, resResolver QueryResponseResolver) error
 		// subscribe(rawEvents string) (chan Event, chan error, error) // TODO: Remove this, or make it public again
 		SubscribeEvents(event EventType, events ...EventType) (chan Event, chan error, error)
+		SubscribeEventsContext(ctx context.Context, event EventType, events ...EventType) (chan Event, chan error, error)
+
+		// Node returns a builder for the `node` commands, applied to the nodes matching the selector.
+		Node(sel Selector) NodeCommand
+		// Desktop returns a builder for the `desktop` commands, applied to the desktops matching the selector.
+		Desktop(sel Selector) DesktopCommand
+		// Monitor returns a builder for the `monitor` commands, applied to the monitors matching the selector.
+		Monitor(sel Selector) MonitorCommand
+		// Rules returns a builder for the `rule` commands.
+		Rules() RuleCommand
+		// Config returns a builder for the `config` commands, applied to the global settings.
+		Config() ConfigCommand
+
+		QueryNodes(sel Selector) ([]ID, error)
+		QueryNodesContext(ctx context.Context, sel Selector) ([]ID, error)
+		QueryDesktops(sel Selector) ([]ID, error)
+		QueryDesktopsContext(ctx context.Context, sel Selector) ([]ID, error)
+		QueryMonitors(sel Selector) ([]ID, error)
+		QueryMonitorsContext(ctx context.Context, sel Selector) ([]ID, error)
+		QueryDesktopNames(sel Selector) ([]string, error)
+		QueryDesktopNamesContext(ctx context.Context, sel Selector) ([]string, error)
+		QueryMonitorNames(sel Selector) ([]string, error)
+		QueryMonitorNamesContext(ctx context.Context, sel Selector) ([]string, error)
+		Tree(sel Selector) (Node, error)
+		TreeContext(ctx context.Context, sel Selector) (Node, error)
+		DesktopTree(sel Selector) (Desktop, error)
+		DesktopTreeContext(ctx context.Context, sel Selector) (Desktop, error)
+		MonitorTree(sel Selector) (Monitor, error)
+		MonitorTreeContext(ctx context.Context, sel Selector) (Monitor, error)
+		DumpState() (State, error)
+		DumpStateContext(ctx context.Context) (State, error)
 	}
 
 	// client holds the socket path, because it needs to initialize a socket connection on each method call.
@@ -31,32 +60,15 @@ type (
 	}
 )
 
-// New returns a client instance with the first unix socket path it finds
-// with a name matching: /tmp/bspwm<host_name>_<display_number>_<screen_number>-socket
+// New returns a client instance for the socket bspc itself would use:
+// the one in $BSPWM_SOCKET, or else the one built from $DISPLAY, as in:
+// /tmp/bspwm<host_name>_<display_number>_<screen_number>-socket
+// If neither exists, the first socket found in /tmp is used. Take a look at DefaultSocketPath to know more.
 // If the value passed in as a logger is nil, logging will be disabled.
 func New(logger Logger) (Client, error) {
-	errSocketFound := errors.New("socket has been found")
-
-	regex, err := regexp.Compile(`^/tmp/\w+_\d+_\d+-socket$`)
+	socketPath, err := DefaultSocketPath()
 	if err != nil {
-		return nil, fmt.Errorf("failed to compile bspwm socket name regex: %v", err)
-	}
-
-	var socketPath string
-	err = filepath.Walk(filepath.Dir("/tmp/"), func(path string, info os.FileInfo, err error) error {
-		if info.IsDir() {
-			return nil
-		}
-
-		if regex.MatchString(path) {
-			socketPath = path
-			return errSocketFound
-		}
-
-		return nil
-	})
-	if err != nil && !errors.Is(err, errSocketFound) {
-		return nil, fmt.Errorf("failed to find bspwm unix socket: %v", err)
+		return nil, fmt.Errorf("failed to find bspwm unix socket: %w", err)
 	}
 
 	return NewWithSocketPath(socketPath, logger)
@@ -79,26 +91,49 @@ func NewWithSocketPath(path string, logger Logger) (Client, error) {
 // response into the provided type. The models provided in this package can be used to construct
 // the response type.
 func (c client) Query(rawCmd string, resResolver QueryResponseResolver) error {
-	// TODO: How can I return a sentinel error if the command is invalid? Need to read errors from the socket.
-	c.logger.Info(fmt.Sprintf("using socket at path %s", c.socketPath)) // TODO: The logger needs to be optional, need to nil-check
+	return c.QueryContext(context.Background(), rawCmd, resResolver)
+}
+
+// QueryContext works like Query, but gives up waiting on bspwm once the context is done.
+// The context's deadline, if any, is applied to the socket connection.
+// If bspwm reports that the command failed, a *CommandError is returned.
+func (c client) QueryContext(ctx context.Context, rawCmd string, resResolver QueryResponseResolver) error {
+	return c.query(ctx, newIPCCommand(rawCmd), resResolver)
+}
+
+// query sends the command as it is, so that its words can contain spaces.
+func (c client) query(ctx context.Context, cmd ipcCommand, resResolver QueryResponseResolver) error {
+	c.logInfo(fmt.Sprintf("using socket at path %s", c.socketPath))
 
 	socketAddr, err := newUnixSocketAddress(c.socketPath)
 	if err != nil {
 		return err
 	}
 
-	ipc, err := newIPCConn(socketAddr)
+	ipc, err := newIPCConn(ctx, socketAddr)
 	if err != nil {
 		return fmt.Errorf("failed to initialize socket connection: %w", err)
 	}
+	defer ipc.Close()
+
+	stopWatching := ipc.watch(ctx)
+	defer stopWatching()
+
+	if err := ipc.Send(cmd); err != nil {
+		if ctxErr := contextError(ctx, err); ctxErr != nil {
+			return ctxErr
+		}
 
-	if err := ipc.Send(ipcCommand(rawCmd)); err != nil {
 		return err
 	}
 
-	resBytes, err := ipc.Receive()
+	resBytes, err := ipc.Receive(ctx)
 	if err != nil {
-		return fmt.Errorf("query failed: %v", err)
+		return fmt.Errorf("query failed: %w", err)
+	}
+
+	if len(resBytes) > 0 && resBytes[0] == failureMessage {
+		return newCommandError(cmd.String(), resBytes[1:])
 	}
 
 	if resResolver == nil {
@@ -115,35 +150,79 @@ func (c client) Query(rawCmd string, resResolver QueryResponseResolver) error {
 // subscribe returns two channels: one for the events published by bspwm
 // and that we subscribe to, and one for the errors that might occur during
 // the subscription.
-// It is a private method, because passing in more than one event can cause errors,
-// as bspwm will sometimes jumble to events together with no clear delimiters that I could identify. TODO
-// (for eg. when you enable monocle mode, `desktop_layout` and `node_remove` events will often be
-// mixed in the same string, with no delimiters between the end of one event, and the beginning of another).
-func (c client) subscribe(rawEvents string) (chan Event, chan error, error) {
-	c.logger.Info(fmt.Sprintf("using socket at path %s", c.socketPath))
+// All the events go through a single connection, so they arrive in the order bspwm published them.
+// Both channels are closed once the connection ends, or the context is done.
+func (c client) subscribe(ctx context.Context, rawEvents string) (chan Event, chan error, error) {
+	c.logInfo(fmt.Sprintf("using socket at path %s", c.socketPath))
 
 	socketAddr, err := newUnixSocketAddress(c.socketPath)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	ipc, err := newIPCConn(socketAddr)
+	ipc, err := newIPCConn(ctx, socketAddr)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to initialize socket connection: %w", err)
 	}
 
 	const subscribeCmd = "subscribe"
 
-	if err := ipc.Send(ipcCommand(subscribeCmd + " " + rawEvents)); err != nil {
+	cmd := newIPCCommand(subscribeCmd + " " + rawEvents)
+	if err := ipc.Send(cmd); err != nil {
+		_ = ipc.Close()
 		return nil, nil, err
 	}
 
-	resCh, errCh := ipc.ReceiveAsync()
+	// Cancelling this context stops the connection, when the subscription ends before it.
+	ctx, cancel := context.WithCancel(ctx)
+
+	resCh, ipcErrCh := ipc.ReceiveAsync(ctx)
+
+	var (
+		eventCh = make(chan Event)
+		errCh   = make(chan error, 1)
+	)
 
-	eventCh := make(chan Event)
 	go func(resCh chan []byte) {
+		defer func() {
+			cancel()
+			close(eventCh)
+			close(errCh)
+		}()
+
+		send := func(ev Event) bool {
+			select {
+			case eventCh <- ev:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
 		for res := range resCh {
-			parts := strings.Split(strings.ReplaceAll(string(res), "\n", ""), " ")
+			// bspwm rejects the subscription itself the same way it rejects any other command.
+			if res[0] == failureMessage {
+				errCh <- newCommandError(cmd.String(), res[1:])
+				return
+			}
+
+			rawEvent := string(res)
+
+			if isReport(rawEvent) {
+				status, err := ParseReport(rawEvent)
+				if err != nil {
+					c.logEventWarning(EventTypeReport, err.Error())
+					continue
+				}
+
+				if !send(Event{Type: EventTypeReport, Payload: status}) {
+					return
+				}
+
+				continue
+			}
+
+			parts := strings.Split(rawEvent, " ")
 			if len(parts) < 2 {
 				c.logEventWarning("unknown", "not enough fields")
 				continue
@@ -162,13 +241,13 @@ func (c client) subscribe(rawEvents string) (chan Event, chan error, error) {
 					continue
 				}
 
-				id, err := hexToID(parts[0])
+				id, err := ParseID(parts[0])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[0]))
 					continue
 				}
 
-				geometry, err := geometryToRectangle(parts[2])
+				geometry, err := ParseRectangle(parts[2])
 				if err != nil {
 					c.logEventWarning(ev.Type, err.Error())
 					continue
@@ -185,7 +264,7 @@ func (c client) subscribe(rawEvents string) (chan Event, chan error, error) {
 					continue
 				}
 
-				id, err := hexToID(parts[0])
+				id, err := ParseID(parts[0])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[0]))
 					continue
@@ -202,7 +281,7 @@ func (c client) subscribe(rawEvents string) (chan Event, chan error, error) {
 					continue
 				}
 
-				id, err := hexToID(parts[0])
+				id, err := ParseID(parts[0])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[0]))
 					continue
@@ -217,13 +296,13 @@ func (c client) subscribe(rawEvents string) (chan Event, chan error, error) {
 					continue
 				}
 
-				srcID, err := hexToID(parts[0])
+				srcID, err := ParseID(parts[0])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[0]))
 					continue
 				}
 
-				dstID, err := hexToID(parts[1])
+				dstID, err := ParseID(parts[1])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[1]))
 					continue
@@ -239,7 +318,7 @@ func (c client) subscribe(rawEvents string) (chan Event, chan error, error) {
 					continue
 				}
 
-				id, err := hexToID(parts[0])
+				id, err := ParseID(parts[0])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[0]))
 					continue
@@ -255,13 +334,13 @@ func (c client) subscribe(rawEvents string) (chan Event, chan error, error) {
 					continue
 				}
 
-				id, err := hexToID(parts[0])
+				id, err := ParseID(parts[0])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[0]))
 					continue
 				}
 
-				geometry, err := geometryToRectangle(parts[1])
+				geometry, err := ParseRectangle(parts[1])
 				if err != nil {
 					c.logEventWarning(ev.Type, err.Error())
 					continue
@@ -277,13 +356,13 @@ func (c client) subscribe(rawEvents string) (chan Event, chan error, error) {
 					continue
 				}
 
-				mID, err := hexToID(parts[0])
+				mID, err := ParseID(parts[0])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[0]))
 					continue
 				}
 
-				dID, err := hexToID(parts[1])
+				dID, err := ParseID(parts[1])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[1]))
 					continue
@@ -300,13 +379,13 @@ func (c client) subscribe(rawEvents string) (chan Event, chan error, error) {
 					continue
 				}
 
-				mID, err := hexToID(parts[0])
+				mID, err := ParseID(parts[0])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[0]))
 					continue
 				}
 
-				dID, err := hexToID(parts[1])
+				dID, err := ParseID(parts[1])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[1]))
 					continue
@@ -324,13 +403,13 @@ func (c client) subscribe(rawEvents string) (chan Event, chan error, error) {
 					continue
 				}
 
-				mID, err := hexToID(parts[0])
+				mID, err := ParseID(parts[0])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[0]))
 					continue
 				}
 
-				dID, err := hexToID(parts[1])
+				dID, err := ParseID(parts[1])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[1]))
 					continue
@@ -346,25 +425,25 @@ func (c client) subscribe(rawEvents string) (chan Event, chan error, error) {
 					continue
 				}
 
-				srcMonitorID, err := hexToID(parts[0])
+				srcMonitorID, err := ParseID(parts[0])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[0]))
 					continue
 				}
 
-				srcDesktopID, err := hexToID(parts[1])
+				srcDesktopID, err := ParseID(parts[1])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[1]))
 					continue
 				}
 
-				dstMonitorID, err := hexToID(parts[2])
+				dstMonitorID, err := ParseID(parts[2])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[2]))
 					continue
 				}
 
-				dstDesktopID, err := hexToID(parts[3])
+				dstDesktopID, err := ParseID(parts[3])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[3]))
 					continue
@@ -392,13 +471,13 @@ func (c client) subscribe(rawEvents string) (chan Event, chan error, error) {
 					continue
 				}
 
-				mID, err := hexToID(parts[0])
+				mID, err := ParseID(parts[0])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[0]))
 					continue
 				}
 
-				dID, err := hexToID(parts[1])
+				dID, err := ParseID(parts[1])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[1]))
 					continue
@@ -422,13 +501,13 @@ func (c client) subscribe(rawEvents string) (chan Event, chan error, error) {
 					continue
 				}
 
-				mID, err := hexToID(parts[0])
+				mID, err := ParseID(parts[0])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[0]))
 					continue
 				}
 
-				dID, err := hexToID(parts[1])
+				dID, err := ParseID(parts[1])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[1]))
 					continue
@@ -451,25 +530,25 @@ func (c client) subscribe(rawEvents string) (chan Event, chan error, error) {
 					continue
 				}
 
-				mID, err := hexToID(parts[0])
+				mID, err := ParseID(parts[0])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[0]))
 					continue
 				}
 
-				dID, err := hexToID(parts[1])
+				dID, err := ParseID(parts[1])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[1]))
 					continue
 				}
 
-				ipID, err := hexToID(parts[2])
+				ipID, err := ParseID(parts[2])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[2]))
 					continue
 				}
 
-				nodeID, err := hexToID(parts[3])
+				nodeID, err := ParseID(parts[3])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[3]))
 					continue
@@ -487,19 +566,19 @@ func (c client) subscribe(rawEvents string) (chan Event, chan error, error) {
 					continue
 				}
 
-				mID, err := hexToID(parts[0])
+				mID, err := ParseID(parts[0])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[0]))
 					continue
 				}
 
-				dID, err := hexToID(parts[1])
+				dID, err := ParseID(parts[1])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[1]))
 					continue
 				}
 
-				nodeID, err := hexToID(parts[2])
+				nodeID, err := ParseID(parts[2])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[2]))
 					continue
@@ -515,37 +594,37 @@ func (c client) subscribe(rawEvents string) (chan Event, chan error, error) {
 					c.logEventWarning(ev.Type, "not enough fields")
 					continue
 				}
-				srcMonitorID, err := hexToID(parts[0])
+				srcMonitorID, err := ParseID(parts[0])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[0]))
 					continue
 				}
 
-				srcDesktopID, err := hexToID(parts[1])
+				srcDesktopID, err := ParseID(parts[1])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[1]))
 					continue
 				}
 
-				srcNodeID, err := hexToID(parts[2])
+				srcNodeID, err := ParseID(parts[2])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[2]))
 					continue
 				}
 
-				dstMonitorID, err := hexToID(parts[3])
+				dstMonitorID, err := ParseID(parts[3])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[3]))
 					continue
 				}
 
-				dstDesktopID, err := hexToID(parts[4])
+				dstDesktopID, err := ParseID(parts[4])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[4]))
 					continue
 				}
 
-				dstNodeID, err := hexToID(parts[5])
+				dstNodeID, err := ParseID(parts[5])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[5]))
 					continue
@@ -577,19 +656,19 @@ func (c client) subscribe(rawEvents string) (chan Event, chan error, error) {
 					continue
 				}
 
-				mID, err := hexToID(parts[0])
+				mID, err := ParseID(parts[0])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[0]))
 					continue
 				}
 
-				dID, err := hexToID(parts[1])
+				dID, err := ParseID(parts[1])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[1]))
 					continue
 				}
 
-				nID, err := hexToID(parts[2])
+				nID, err := ParseID(parts[2])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[2]))
 					continue
@@ -610,24 +689,24 @@ func (c client) subscribe(rawEvents string) (chan Event, chan error, error) {
 					}
 				}
 			case EventTypeNodePreselect:
-				if len(parts) != 5 || len(parts) != 6 { // TODO: there is an optional field when `cancel` is present.
+				if len(parts) != 4 && len(parts) != 5 { // `cancel` is the only field without a value.
 					c.logEventWarning(ev.Type, "not enough fields")
 					continue
 				}
 
-				mID, err := hexToID(parts[0])
+				mID, err := ParseID(parts[0])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[0]))
 					continue
 				}
 
-				dID, err := hexToID(parts[1])
+				dID, err := ParseID(parts[1])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[1]))
 					continue
 				}
 
-				nID, err := hexToID(parts[2])
+				nID, err := ParseID(parts[2])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[2]))
 					continue
@@ -642,29 +721,34 @@ func (c client) subscribe(rawEvents string) (chan Event, chan error, error) {
 				var (
 					isCancel  *bool
 					ratio     *float64
-					direction *SplitType
+					direction *DirectionType
 				)
 
-				switch parts[4] {
+				if parts[3] != fieldCancel && len(parts) != 5 {
+					c.logEventWarning(ev.Type, "not enough fields")
+					continue
+				}
+
+				switch parts[3] {
 				case fieldCancel:
 					cancel := true
 					isCancel = &cancel
 				case fieldRatio:
-					r, err := strconv.ParseFloat(parts[5], 64)
+					r, err := strconv.ParseFloat(parts[4], 64)
 					if err != nil {
 						c.logEventWarning(ev.Type, "not enough fields")
 						continue
 					}
 					ratio = &r
 				case fieldDirection:
-					d := SplitType(parts[5])
+					d := DirectionType(parts[4])
 					if !d.IsValid() {
-						c.logEventWarning(ev.Type, fmt.Sprintf("invalid split type: %s", d))
+						c.logEventWarning(ev.Type, fmt.Sprintf("invalid direction: %s", d))
 						continue
 					}
 					direction = &d
 				default:
-					c.logEventWarning(ev.Type, fmt.Sprintf("invalid field '%s'", parts[4]))
+					c.logEventWarning(ev.Type, fmt.Sprintf("invalid field '%s'", parts[3]))
 					continue
 				}
 
@@ -682,7 +766,7 @@ func (c client) subscribe(rawEvents string) (chan Event, chan error, error) {
 					continue
 				}
 
-				n1ID, err := hexToID(parts[0])
+				n1ID, err := ParseID(parts[0])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[0]))
 					continue
@@ -694,7 +778,7 @@ func (c client) subscribe(rawEvents string) (chan Event, chan error, error) {
 					continue
 				}
 
-				n2ID, err := hexToID(parts[2])
+				n2ID, err := ParseID(parts[2])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[2]))
 					continue
@@ -711,25 +795,25 @@ func (c client) subscribe(rawEvents string) (chan Event, chan error, error) {
 					continue
 				}
 
-				mID, err := hexToID(parts[0])
+				mID, err := ParseID(parts[0])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[0]))
 					continue
 				}
 
-				dID, err := hexToID(parts[1])
+				dID, err := ParseID(parts[1])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[1]))
 					continue
 				}
 
-				nID, err := hexToID(parts[2])
+				nID, err := ParseID(parts[2])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[2]))
 					continue
 				}
 
-				geometry, err := geometryToRectangle(parts[3])
+				geometry, err := ParseRectangle(parts[3])
 				if err != nil {
 					c.logEventWarning(ev.Type, err.Error())
 					continue
@@ -747,19 +831,19 @@ func (c client) subscribe(rawEvents string) (chan Event, chan error, error) {
 					continue
 				}
 
-				mID, err := hexToID(parts[0])
+				mID, err := ParseID(parts[0])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[0]))
 					continue
 				}
 
-				dID, err := hexToID(parts[1])
+				dID, err := ParseID(parts[1])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[1]))
 					continue
 				}
 
-				nID, err := hexToID(parts[2])
+				nID, err := ParseID(parts[2])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[2]))
 					continue
@@ -800,27 +884,27 @@ func (c client) subscribe(rawEvents string) (chan Event, chan error, error) {
 					continue
 				}
 
-				mID, err := hexToID(parts[0])
+				mID, err := ParseID(parts[0])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[0]))
 					continue
 				}
 
-				dID, err := hexToID(parts[1])
+				dID, err := ParseID(parts[1])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[1]))
 					continue
 				}
 
-				nID, err := hexToID(parts[2])
+				nID, err := ParseID(parts[2])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[2]))
 					continue
 				}
 
-				state := StateType(parts[3])
-				if !state.IsValid() {
-					c.logEventWarning(ev.Type, fmt.Sprintf("invalid state %s", state))
+				flag := FlagType(parts[3])
+				if !flag.IsValid() {
+					c.logEventWarning(ev.Type, fmt.Sprintf("invalid flag %s", flag))
 					continue
 				}
 
@@ -840,11 +924,11 @@ func (c client) subscribe(rawEvents string) (chan Event, chan error, error) {
 					continue
 				}
 
-				ev.Payload = EventNodeState{
+				ev.Payload = EventNodeFlag{
 					MonitorID:  mID,
 					DesktopID:  dID,
 					NodeID:     nID,
-					State:      state,
+					Flag:       flag,
 					WasEnabled: wasEnabled,
 				}
 			case EventTypeNodeLayer:
@@ -853,19 +937,19 @@ func (c client) subscribe(rawEvents string) (chan Event, chan error, error) {
 					continue
 				}
 
-				mID, err := hexToID(parts[0])
+				mID, err := ParseID(parts[0])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[0]))
 					continue
 				}
 
-				dID, err := hexToID(parts[1])
+				dID, err := ParseID(parts[1])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[1]))
 					continue
 				}
 
-				nID, err := hexToID(parts[2])
+				nID, err := ParseID(parts[2])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[2]))
 					continue
@@ -889,19 +973,19 @@ func (c client) subscribe(rawEvents string) (chan Event, chan error, error) {
 					continue
 				}
 
-				mID, err := hexToID(parts[0])
+				mID, err := ParseID(parts[0])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[0]))
 					continue
 				}
 
-				dID, err := hexToID(parts[1])
+				dID, err := ParseID(parts[1])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[1]))
 					continue
 				}
 
-				nID, err := hexToID(parts[2])
+				nID, err := ParseID(parts[2])
 				if err != nil {
 					c.logEventWarning(ev.Type, fmt.Sprintf("invalid id %s", parts[2]))
 					continue
@@ -931,7 +1015,13 @@ func (c client) subscribe(rawEvents string) (chan Event, chan error, error) {
 				continue
 			}
 
-			eventCh <- ev
+			if !send(ev) {
+				return
+			}
+		}
+
+		if err, ok := <-ipcErrCh; ok {
+			errCh <- err
 		}
 	}(resCh)
 
@@ -940,53 +1030,25 @@ func (c client) subscribe(rawEvents string) (chan Event, chan error, error) {
 
 // SubscribeEvents takes in one or more of the available events in this package and calls Subscribe
 // with the appropriate raw command. Take a look at Subscribe to know more.
-// It currently uses a socket connection for each event as to avoid having different events jumbled together
-// by bspwm. Haven't found a way to tell those "glued" events apart. TODO.
 func (c client) SubscribeEvents(event EventType, moreEvents ...EventType) (chan Event, chan error, error) {
-	// TODO: Refactor this code to be more maintainable.
-
-	var (
-		eventsChannel = make(chan Event)
-		errorsChannel = make(chan error)
-	)
-
-	events := []EventType{event}
-	events = append(events, moreEvents...)
-
-	var (
-		eventChs []chan Event
-		errChs   []chan error
-	)
-
-	for _, ev := range events {
-		eventCh, errCh, err := c.subscribe(string(ev))
-		if err != nil {
-			return nil, nil, err
-		}
+	return c.SubscribeEventsContext(context.Background(), event, moreEvents...)
+}
 
-		eventChs = append(eventChs, eventCh)
-		errChs = append(errChs, errCh)
+// SubscribeEventsContext works like SubscribeEvents, but stops the subscription once the context is done.
+// When that happens, the connection is closed, and so are the returned channels.
+func (c client) SubscribeEventsContext(ctx context.Context, event EventType, moreEvents ...EventType) (chan Event, chan error, error) {
+	rawEvents := make([]string, 0, len(moreEvents)+1)
+	for _, ev := range append([]EventType{event}, moreEvents...) {
+		rawEvents = append(rawEvents, string(ev))
 	}
 
-	for i := range eventChs {
-		var (
-			evCh  = eventChs[i]
-			errCh = errChs[i]
-		)
+	return c.subscribe(ctx, strings.Join(rawEvents, " "))
+}
 
-		go func() {
-			for {
-				select {
-				case ev := <-evCh:
-					eventsChannel <- ev
-				case err := <-errCh:
-					errorsChannel <- err
-				}
-			}
-		}()
+func (c client) logInfo(msg string) {
+	if l := c.logger; l != nil {
+		l.Info(msg)
 	}
-
-	return eventsChannel, errorsChannel, nil
 }
 
 func (c client) logEventWarning(ev EventType, msg string) {
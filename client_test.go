@@ -0,0 +1,413 @@
+package bspc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diogox/bspc-go"
+	"github.com/diogox/bspc-go/bspctest"
+)
+
+func newTestClient(t *testing.T) (bspc.Client, *bspctest.Server) {
+	return bspctest.NewClient(t)
+}
+
+func TestClient_Query(t *testing.T) {
+	t.Run("should resolve the response", func(t *testing.T) {
+		c, srv := newTestClient(t)
+		srv.Respond("query -N -n focused", "0x00E00003\n")
+
+		var id bspc.ID
+		require.NoError(t, c.Query("query -N -n focused", bspc.ToID(&id)))
+
+		assert.Equal(t, bspc.ID(0x00E00003), id)
+	})
+
+	t.Run("should resolve responses longer than a single read", func(t *testing.T) {
+		c, srv := newTestClient(t)
+
+		want := bspc.State{ClientsCount: 3}
+		for i := 0; i < 50; i++ {
+			want.FocusHistory = append(want.FocusHistory, bspc.StateFocusHistoryEntry{NodeID: bspc.ID(i)})
+		}
+		srv.RespondJSON("wm --dump-state", want)
+
+		var got bspc.State
+		require.NoError(t, c.Query("wm --dump-state", bspc.ToStruct(&got)))
+
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("should return a command error when bspwm reports a failure", func(t *testing.T) {
+		c, srv := newTestClient(t)
+		srv.Fail("node -f nonsense", "node: Invalid descriptor found in 'nonsense'.\n")
+
+		err := c.Query("node -f nonsense", nil)
+
+		var cmdErr *bspc.CommandError
+		require.True(t, errors.As(err, &cmdErr))
+		assert.Equal(t, "node -f nonsense", cmdErr.Command)
+		assert.Equal(t, "node: Invalid descriptor found in 'nonsense'.", cmdErr.Message)
+		assert.True(t, errors.Is(err, bspc.ErrInvalidSelector))
+	})
+
+	t.Run("should give up once the context's deadline is exceeded", func(t *testing.T) {
+		c, srv := newTestClient(t)
+		srv.Hang("wm --dump-state")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		err := c.QueryContext(ctx, "wm --dump-state", nil)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded), err)
+	})
+
+	t.Run("should not connect once the context's deadline has passed", func(t *testing.T) {
+		c, srv := newTestClient(t)
+
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+
+		err := c.QueryContext(ctx, "wm --dump-state", nil)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded), err)
+
+		_, _, err = c.SubscribeEventsContext(ctx, bspc.EventTypeNodeFocus)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded), err)
+
+		assert.Empty(t, srv.Commands())
+	})
+}
+
+func TestClient_Node(t *testing.T) {
+	t.Run("should send the command's words", func(t *testing.T) {
+		c, srv := newTestClient(t)
+
+		require.NoError(t, c.Node(bspc.ByID(0x00E00003)).ToDesktop(bspc.ByName("I"), true))
+		require.NoError(t, c.Node(bspc.Selector{}).Flag(bspc.FlagTypeSticky, true))
+		require.NoError(t, c.Node(bspc.Focused()).Resize(bspc.HandleTypeBottomRight, -10, 20))
+
+		assert.Equal(t, []string{
+			"node 0x00E00003 --to-desktop I --follow",
+			"node --flag sticky=on",
+			"node focused --resize bottom_right -10 20",
+		}, srv.Commands())
+	})
+
+	t.Run("should not send invalid arguments", func(t *testing.T) {
+		c, srv := newTestClient(t)
+
+		err := c.Node(bspc.Focused()).State("sideways")
+		assert.True(t, errors.Is(err, bspc.ErrInvalidArgument))
+
+		err = c.Node(bspc.Focused()).Flag(bspc.FlagTypeUrgent, true)
+		assert.True(t, errors.Is(err, bspc.ErrInvalidArgument))
+
+		assert.Empty(t, srv.Commands())
+	})
+}
+
+func TestClient_Desktop(t *testing.T) {
+	t.Run("should send the command's words", func(t *testing.T) {
+		c, srv := newTestClient(t)
+
+		require.NoError(t, c.Desktop(bspc.Index(2)).Layout(bspc.LayoutTypeMonocle))
+		require.NoError(t, c.Desktop(bspc.Selector{}).Rename("www"))
+
+		assert.Equal(t, []string{
+			"desktop ^2 --layout monocle",
+			"desktop --rename www",
+		}, srv.Commands())
+	})
+
+	t.Run("should not send invalid arguments", func(t *testing.T) {
+		c, srv := newTestClient(t)
+
+		err := c.Desktop(bspc.Focused()).Layout("sideways")
+
+		assert.True(t, errors.Is(err, bspc.ErrInvalidArgument))
+		assert.Empty(t, srv.Commands())
+	})
+}
+
+func TestClient_Monitor(t *testing.T) {
+	t.Run("should send the command's words", func(t *testing.T) {
+		c, srv := newTestClient(t)
+
+		require.NoError(t, c.Monitor(bspc.ByName("eDP-1")).AddDesktops("I", "II"))
+		require.NoError(t, c.Monitor(bspc.Selector{}).Rename("HDMI-1"))
+
+		assert.Equal(t, []string{
+			"monitor eDP-1 --add-desktops I II",
+			"monitor --rename HDMI-1",
+		}, srv.Commands())
+	})
+
+	t.Run("should not send invalid arguments", func(t *testing.T) {
+		c, srv := newTestClient(t)
+
+		err := c.Monitor(bspc.Focused()).ResetDesktops()
+
+		assert.True(t, errors.Is(err, bspc.ErrInvalidArgument))
+		assert.Empty(t, srv.Commands())
+	})
+}
+
+func TestClient_QueryNodes(t *testing.T) {
+	t.Run("should return the matching IDs", func(t *testing.T) {
+		c, srv := newTestClient(t)
+		srv.Respond("query --nodes --node .leaf.!hidden", "0x00E00003\n0x00E00004\n")
+
+		ids, err := c.QueryNodes(bspc.Selector{}.With(bspc.ModifierLeaf, bspc.Not(bspc.FlagModifier(bspc.FlagTypeHidden))))
+		require.NoError(t, err)
+
+		assert.Equal(t, []bspc.ID{0x00E00003, 0x00E00004}, ids)
+	})
+
+	t.Run("should return an empty slice when nothing matches", func(t *testing.T) {
+		c, srv := newTestClient(t)
+		srv.Fail("query --nodes --node .hidden", "")
+
+		ids, err := c.QueryNodes(bspc.Selector{}.With(bspc.FlagModifier(bspc.FlagTypeHidden)))
+		require.NoError(t, err)
+
+		assert.Empty(t, ids)
+	})
+
+	t.Run("should give up once the context's deadline is exceeded", func(t *testing.T) {
+		c, srv := newTestClient(t)
+		srv.Hang("query --nodes")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := c.QueryNodesContext(ctx, bspc.Selector{})
+		assert.True(t, errors.Is(err, context.DeadlineExceeded), err)
+	})
+}
+
+func TestClient_QueryNames(t *testing.T) {
+	t.Run("should return the names of the matching desktops", func(t *testing.T) {
+		c, srv := newTestClient(t)
+		srv.Respond("query --desktops --desktop .occupied --names", "I\nII\n")
+		srv.Respond("query --desktops --names", "I\nII\nIII\n")
+
+		names, err := c.QueryDesktopNames(bspc.Selector{}.With(bspc.ModifierOccupied))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"I", "II"}, names)
+
+		names, err = c.QueryDesktopNames(bspc.Selector{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"I", "II", "III"}, names)
+	})
+
+	t.Run("should return the names of the matching monitors", func(t *testing.T) {
+		c, srv := newTestClient(t)
+		srv.Respond("query --monitors --names", "eDP-1\nHDMI-1\n")
+
+		names, err := c.QueryMonitorNames(bspc.Selector{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"eDP-1", "HDMI-1"}, names)
+	})
+
+	t.Run("should return an empty slice when nothing matches", func(t *testing.T) {
+		c, srv := newTestClient(t)
+		srv.Fail("query --monitors --monitor .!focused --names", "")
+
+		names, err := c.QueryMonitorNames(bspc.Selector{}.With(bspc.Not(bspc.ModifierFocused)))
+		require.NoError(t, err)
+		assert.Empty(t, names)
+	})
+}
+
+func TestClient_Tree(t *testing.T) {
+	t.Run("should return the tree of the matching node", func(t *testing.T) {
+		c, srv := newTestClient(t)
+		srv.RespondJSON("query --tree --node 0x00E00003", bspc.Node{ID: 0x00E00003, SplitType: bspc.SplitTypeVertical})
+
+		n, err := c.Tree(bspc.ByID(0x00E00003))
+		require.NoError(t, err)
+		assert.Equal(t, bspc.ID(0x00E00003), n.ID)
+	})
+
+	t.Run("should return the tree of the focused items, given no selector", func(t *testing.T) {
+		c, srv := newTestClient(t)
+		srv.RespondJSON("query --tree --node", bspc.Node{ID: 0x00E00003, SplitType: bspc.SplitTypeVertical})
+		srv.RespondJSON("query --tree --desktop", bspc.Desktop{ID: 0x00200003, Name: "I"})
+		srv.RespondJSON("query --tree --monitor", bspc.Monitor{ID: 0x00200002, Name: "eDP-1"})
+
+		n, err := c.Tree(bspc.Selector{})
+		require.NoError(t, err)
+		assert.Equal(t, bspc.ID(0x00E00003), n.ID)
+
+		d, err := c.DesktopTree(bspc.Selector{})
+		require.NoError(t, err)
+		assert.Equal(t, "I", d.Name)
+
+		m, err := c.MonitorTree(bspc.Selector{})
+		require.NoError(t, err)
+		assert.Equal(t, "eDP-1", m.Name)
+	})
+
+	t.Run("should return the matching desktop and monitor", func(t *testing.T) {
+		c, srv := newTestClient(t)
+		srv.RespondJSON("query --tree --desktop ^2", bspc.Desktop{ID: 0x00200004, Name: "II"})
+		srv.RespondJSON("query --tree --monitor primary", bspc.Monitor{ID: 0x00200002, Name: "eDP-1"})
+
+		d, err := c.DesktopTree(bspc.Index(2))
+		require.NoError(t, err)
+		assert.Equal(t, bspc.ID(0x00200004), d.ID)
+
+		m, err := c.MonitorTreeContext(context.Background(), bspc.Primary())
+		require.NoError(t, err)
+		assert.Equal(t, bspc.ID(0x00200002), m.ID)
+	})
+}
+
+func TestClient_DumpState(t *testing.T) {
+	t.Run("should return the whole state", func(t *testing.T) {
+		c, srv := newTestClient(t)
+		srv.RespondJSON("wm --dump-state", bspc.State{FocusedMonitorID: 0x00200002, ClientsCount: 3})
+
+		st, err := c.DumpState()
+		require.NoError(t, err)
+		assert.Equal(t, bspc.State{FocusedMonitorID: 0x00200002, ClientsCount: 3}, st)
+	})
+
+	t.Run("should give up once the context's deadline is exceeded", func(t *testing.T) {
+		c, srv := newTestClient(t)
+		srv.Hang("wm --dump-state")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := c.DumpStateContext(ctx)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded), err)
+	})
+}
+
+func TestClient_SubscribeEvents(t *testing.T) {
+	t.Run("should receive every event through a single connection, in order", func(t *testing.T) {
+		c, srv := newTestClient(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		eventCh, _, err := c.SubscribeEventsContext(ctx, bspc.EventTypeNodeRemove, bspc.EventTypeDesktopLayout, bspc.EventTypeReport)
+		require.NoError(t, err)
+
+		srv.WaitForSubscribers(1)
+		assert.Equal(t, []string{"subscribe node_remove desktop_layout report"}, srv.Commands())
+
+		// Glued together, just like bspwm does when they happen in quick succession.
+		srv.Publish(
+			"desktop_layout 0x00200002 0x00200003 monocle",
+			"node_remove 0x00200002 0x00200003 0x00E00003",
+			"WMeDP-1:OI:LM",
+		)
+
+		assert.Equal(t, bspc.Event{
+			Type: bspc.EventTypeDesktopLayout,
+			Payload: bspc.EventDesktopLayout{
+				MonitorID:     0x00200002,
+				DesktopID:     0x00200003,
+				DesktopLayout: bspc.LayoutTypeMonocle,
+			},
+		}, <-eventCh)
+		assert.Equal(t, bspc.Event{
+			Type: bspc.EventTypeNodeRemove,
+			Payload: bspc.EventNodeRemove{
+				MonitorID: 0x00200002,
+				DesktopID: 0x00200003,
+				NodeID:    0x00E00003,
+			},
+		}, <-eventCh)
+
+		report := <-eventCh
+		assert.Equal(t, bspc.EventTypeReport, report.Type)
+		assert.Equal(t, bspc.LayoutTypeMonocle, report.Payload.(bspc.ReportStatus).Monitors[0].Layout)
+	})
+
+	t.Run("should parse reports", func(t *testing.T) {
+		c, srv := newTestClient(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		eventCh, _, err := c.SubscribeEventsContext(ctx, bspc.EventTypeReport)
+		require.NoError(t, err)
+
+		srv.WaitForSubscribers(1)
+		srv.Publish("WMeDP-1:OI:LM")
+
+		report := <-eventCh
+		assert.Equal(t, bspc.EventTypeReport, report.Type)
+		assert.Equal(t, bspc.LayoutTypeMonocle, report.Payload.(bspc.ReportStatus).Monitors[0].Layout)
+	})
+
+	t.Run("should parse preselection directions", func(t *testing.T) {
+		c, srv := newTestClient(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		eventCh, _, err := c.SubscribeEventsContext(ctx, bspc.EventTypeNodePreselect)
+		require.NoError(t, err)
+
+		srv.WaitForSubscribers(1)
+		srv.Publish(
+			"node_presel 0x00200002 0x00200003 0x00E00003 dir west",
+			"node_presel 0x00200002 0x00200003 0x00E00003 cancel",
+		)
+
+		dir := bspc.DirectionTypeLeft
+		assert.Equal(t, bspc.EventNodePreselect{
+			MonitorID:      0x00200002,
+			DesktopID:      0x00200003,
+			NodeID:         0x00E00003,
+			SplitDirection: &dir,
+		}, (<-eventCh).Payload)
+
+		isCancel := true
+		assert.Equal(t, bspc.EventNodePreselect{
+			MonitorID: 0x00200002,
+			DesktopID: 0x00200003,
+			NodeID:    0x00E00003,
+			IsCancel:  &isCancel,
+		}, (<-eventCh).Payload)
+	})
+
+	t.Run("should close the channels once the context is done", func(t *testing.T) {
+		c, srv := newTestClient(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		eventCh, errCh, err := c.SubscribeEventsContext(ctx, bspc.EventTypeNodeFocus)
+		require.NoError(t, err)
+
+		srv.WaitForSubscribers(1)
+		cancel()
+
+		_, ok := <-eventCh
+		assert.False(t, ok)
+		_, ok = <-errCh
+		assert.False(t, ok)
+	})
+
+	t.Run("should report a rejected subscription", func(t *testing.T) {
+		c, srv := newTestClient(t)
+		srv.Fail("subscribe nonsense", "subscribe: Invalid argument: 'nonsense'.")
+
+		eventCh, errCh, err := c.SubscribeEvents("nonsense")
+		require.NoError(t, err)
+
+		assert.True(t, errors.Is(<-errCh, bspc.ErrInvalidArgument))
+
+		_, ok := <-eventCh
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,276 @@
+// Command bspc-layout keeps bspwm desktops in the shape of layouts bspwm doesn't have, such as tall or grid.
+//
+// Usage:
+//
+//	bspc-layout daemon [-default LAYOUT] [-masters COUNT] [-ratio RATIO]
+//	bspc-layout set DESKTOP_SEL LAYOUT
+//	bspc-layout get DESKTOP_SEL
+//
+// The daemon arranges the desktops as windows come and go, and listens on a unix socket for the layouts
+// to give them, which the set and get commands talk to. Layouts are forgotten when the daemon stops.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/diogox/bspc-go"
+	"github.com/diogox/bspc-go/layouts"
+)
+
+const usage = `usage:
+  bspc-layout daemon [-default LAYOUT] [-masters COUNT] [-ratio RATIO]
+  bspc-layout set DESKTOP_SEL LAYOUT
+  bspc-layout get DESKTOP_SEL`
+
+type logger struct{}
+
+func (l logger) Info(msg string) {
+	fmt.Fprintln(os.Stderr, msg)
+}
+
+func (l logger) Warn(msg string) {
+	fmt.Fprintln(os.Stderr, msg)
+}
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+
+	switch args[0] {
+	case "daemon":
+		return daemon(args[1:])
+	case "set":
+		if len(args) != 3 {
+			return errors.New(usage)
+		}
+
+		return request("set", args[1], args[2])
+	case "get":
+		if len(args) != 2 {
+			return errors.New(usage)
+		}
+
+		return request("get", args[1])
+	}
+
+	return errors.New(usage)
+}
+
+func daemon(args []string) error {
+	fs := flag.NewFlagSet("daemon", flag.ContinueOnError)
+	defaultLayout := fs.String("default", "", "layout of the desktops that weren't given one")
+	masters := fs.Int("masters", 1, "number of windows in the master area of the master-stack layout")
+	ratio := fs.Float64("ratio", 0.5, "share of the desktop taken by the master area")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// The layouts would silently fall back to their defaults otherwise.
+	if *masters < 1 {
+		return fmt.Errorf("%w: invalid masters count %d, it must be at least 1", bspc.ErrInvalidArgument, *masters)
+	}
+
+	if *ratio <= 0 || *ratio >= 1 {
+		return fmt.Errorf("%w: invalid ratio %v, it must be between 0 and 1", bspc.ErrInvalidArgument, *ratio)
+	}
+
+	c, err := bspc.New(logger{})
+	if err != nil {
+		return err
+	}
+
+	e := layouts.NewEngine(c, logger{}, layouts.Options{MasterCount: *masters, MasterRatio: *ratio})
+	if *defaultLayout != "" {
+		layout, err := layouts.ParseName(*defaultLayout)
+		if err != nil {
+			return err
+		}
+
+		e.SetDefaultLayout(layout)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		<-sigCh
+		cancel()
+	}()
+
+	path, err := socketPath()
+	if err != nil {
+		return err
+	}
+
+	l, err := listen(path)
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(path)
+
+	go func() {
+		<-ctx.Done()
+		_ = l.Close()
+	}()
+
+	go serve(ctx, l, c, e)
+
+	if err := e.Run(ctx); !errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	return nil
+}
+
+// serve answers the set and get requests, one line each.
+func serve(ctx context.Context, l net.Listener, c bspc.Client, e *layouts.Engine) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		if err == nil {
+			res, err := handle(ctx, c, e, strings.Fields(line))
+			if err != nil {
+				res = "error: " + err.Error()
+			}
+
+			_, _ = fmt.Fprintln(conn, res)
+		}
+
+		_ = conn.Close()
+	}
+}
+
+func handle(ctx context.Context, c bspc.Client, e *layouts.Engine, words []string) (string, error) {
+	if len(words) < 2 {
+		return "", errors.New("malformed request")
+	}
+
+	sel, err := bspc.ParseSelector(words[1])
+	if err != nil {
+		return "", err
+	}
+
+	ids, err := c.QueryDesktops(sel)
+	if err != nil {
+		return "", err
+	}
+
+	if len(ids) == 0 {
+		return "", fmt.Errorf("no desktop matches %s", words[1])
+	}
+
+	switch {
+	case words[0] == "get":
+		layout, _ := e.Layout(ids[0])
+		return string(layout), nil
+	case words[0] == "set" && len(words) == 3:
+		layout, err := layouts.ParseName(words[2])
+		if err != nil {
+			return "", err
+		}
+
+		e.SetLayout(ids[0], layout)
+
+		return "", e.Arrange(ctx, ids[0])
+	}
+
+	return "", errors.New("malformed request")
+}
+
+// request sends a request to the daemon, and prints its response.
+func request(words ...string) error {
+	path, err := socketPath()
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return fmt.Errorf("failed to reach the daemon: %w", err)
+	}
+
+	defer conn.Close()
+
+	if _, err := fmt.Fprintln(conn, strings.Join(words, " ")); err != nil {
+		return err
+	}
+
+	res, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("failed to read the daemon's response: %w", err)
+	}
+
+	res = strings.TrimSuffix(res, "\n")
+	if strings.HasPrefix(res, "error: ") {
+		return errors.New(strings.TrimPrefix(res, "error: "))
+	}
+
+	if res != "" {
+		fmt.Println(res)
+	}
+
+	return nil
+}
+
+// listen listens on the daemon's socket. A socket left behind by a daemon that didn't stop cleanly is
+// replaced, but one that's still answering belongs to a running daemon, which is left alone.
+func listen(path string) (net.Listener, error) {
+	if conn, err := net.Dial("unix", path); err == nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("a daemon is already listening on %s", path)
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to remove the stale socket %s: %w", path, err)
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+
+	return l, nil
+}
+
+// socketPath returns the path of the daemon's socket, in $XDG_RUNTIME_DIR if it's set.
+// Each display gets its own daemon, the way each one gets its own bspwm.
+func socketPath() (string, error) {
+	d, err := bspc.ParseDisplay(os.Getenv("DISPLAY"))
+	if err != nil {
+		return "", fmt.Errorf("failed to find the display: %w", err)
+	}
+
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		dir = os.TempDir()
+	}
+
+	return filepath.Join(dir, fmt.Sprintf("bspc-layout_%d_%s_%d_%d.sock", os.Getuid(), d.Host, d.Number, d.Screen)), nil
+}
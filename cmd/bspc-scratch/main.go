@@ -0,0 +1,86 @@
+// Command bspc-scratch toggles a scratchpad window, launching it when it's gone.
+//
+// Usage:
+//
+//	bspc-scratch [-class CLASS] [-instance INSTANCE] [-geometry WxH[+X+Y]] [-sticky] [-action toggle|show|hide] -- COMMAND...
+//
+// For instance, bound to a key in sxhkd:
+//
+//	bspc-scratch -instance dropdown -geometry 1200x600 -- alacritty --class dropdown
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/diogox/bspc-go"
+	"github.com/diogox/bspc-go/scratchpad"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("bspc-scratch", flag.ContinueOnError)
+	class := fs.String("class", "", "class of the scratchpad's window")
+	instance := fs.String("instance", "", "instance of the scratchpad's window")
+	geometry := fs.String("geometry", "", "floating geometry of the window, relative to the focused monitor, as in: 1200x600 or 1200x600+100+50")
+	sticky := fs.Bool("sticky", false, "keep the window on the focused desktop while it's shown")
+	action := fs.String("action", "toggle", "what to do with the scratchpad: toggle, show or hide")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *class == "" && *instance == "" {
+		return errors.New("either -class or -instance is required")
+	}
+
+	s := scratchpad.Scratchpad{
+		Class:    *class,
+		Instance: *instance,
+		Command:  fs.Args(),
+		Sticky:   *sticky,
+	}
+
+	if *geometry != "" {
+		g := *geometry
+		if !strings.ContainsAny(g, "+-") {
+			g += "+0+0"
+		}
+
+		r, err := bspc.ParseRectangle(g)
+		if err != nil {
+			return err
+		}
+
+		s.Rectangle = r
+	}
+
+	c, err := bspc.New(nil)
+	if err != nil {
+		return err
+	}
+
+	m := scratchpad.NewManager(c, nil)
+	ctx := context.Background()
+
+	switch *action {
+	case "toggle":
+		return m.Toggle(ctx, s)
+	case "show":
+		return m.Show(ctx, s)
+	case "hide":
+		return m.Hide(ctx, s)
+	}
+
+	return fmt.Errorf("invalid action %s", *action)
+}
@@ -0,0 +1,124 @@
+package bspc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ConfigCommand builds and sends `config` commands, for the global settings or, once scoped,
+// for the settings of the monitors, desktops or nodes matching a selector.
+// Values are validated against the setting's type before being sent, in which case the returned
+// error matches ErrInvalidArgument. Otherwise, a *CommandError is returned if bspwm rejects them.
+type ConfigCommand struct {
+	client   client
+	ctx      context.Context
+	scope    SettingScope
+	selector Selector
+}
+
+// Config returns a builder for the `config` commands, applied to the global settings.
+func (c client) Config() ConfigCommand {
+	return ConfigCommand{
+		client: c,
+		ctx:    context.Background(),
+		scope:  SettingScopeGlobal,
+	}
+}
+
+// WithContext returns a copy of the builder, that sends its commands under the given context.
+func (cc ConfigCommand) WithContext(ctx context.Context) ConfigCommand {
+	cc.ctx = ctx
+	return cc
+}
+
+// Monitor returns a copy of the builder, applied to the settings of the monitors matching the selector.
+// The zero selector targets the focused monitor.
+func (cc ConfigCommand) Monitor(sel Selector) ConfigCommand {
+	return cc.scoped(SettingScopeMonitor, sel)
+}
+
+// Desktop returns a copy of the builder, applied to the settings of the desktops matching the selector.
+// The zero selector targets the focused desktop.
+func (cc ConfigCommand) Desktop(sel Selector) ConfigCommand {
+	return cc.scoped(SettingScopeDesktop, sel)
+}
+
+// Node returns a copy of the builder, applied to the settings of the nodes matching the selector.
+// The zero selector targets the focused node.
+func (cc ConfigCommand) Node(sel Selector) ConfigCommand {
+	return cc.scoped(SettingScopeNode, sel)
+}
+
+// Get returns the setting's value, as the Go type matching the setting's type.
+func (cc ConfigCommand) Get(setting Setting) (interface{}, error) {
+	cmd, err := cc.command(setting)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+
+	err = cc.client.query(cc.ctx, cmd, func(payload []byte) error {
+		v, err := setting.parse(strings.TrimSpace(string(payload)))
+		if err != nil {
+			return fmt.Errorf("failed to parse setting %s: %w", setting.Name, err)
+		}
+
+		value = v
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// Set sets the setting's value. It must be of the Go type matching the setting's type.
+func (cc ConfigCommand) Set(setting Setting, value interface{}) error {
+	cmd, err := cc.command(setting)
+	if err != nil {
+		return err
+	}
+
+	v, err := setting.format(value)
+	if err != nil {
+		return err
+	}
+
+	return cc.client.query(cc.ctx, append(cmd, v), nil)
+}
+
+func (cc ConfigCommand) scoped(scope SettingScope, sel Selector) ConfigCommand {
+	if sel.IsZero() {
+		sel = Focused()
+	}
+
+	cc.scope = scope
+	cc.selector = sel
+
+	return cc
+}
+
+func (cc ConfigCommand) command(setting Setting) (ipcCommand, error) {
+	if setting.Name == "" {
+		return nil, fmt.Errorf("%w: empty setting name", ErrInvalidArgument)
+	}
+
+	if !setting.HasScope(cc.scope) {
+		return nil, fmt.Errorf("%w: setting %s can't be applied to a %s", ErrInvalidArgument, setting.Name, cc.scope)
+	}
+
+	if (setting.Type == SettingTypeEnum || setting.Type == SettingTypePointerModifier) && setting.enumValidator() == nil {
+		return nil, fmt.Errorf("%w: unknown values for %s", ErrInvalidArgument, setting.Name)
+	}
+
+	cmd := ipcCommand{"config"}
+	if cc.scope != SettingScopeGlobal {
+		cmd = append(cmd, "--"+string(cc.scope), cc.selector.String())
+	}
+
+	return append(cmd, setting.Name), nil
+}
@@ -0,0 +1,116 @@
+package bspc_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diogox/bspc-go"
+)
+
+func TestConfigCommand(t *testing.T) {
+	t.Run("should send the settings' values", func(t *testing.T) {
+		c, srv := newTestClient(t)
+
+		require.NoError(t, c.Config().Set(bspc.SettingSplitRatio, 0.52))
+		require.NoError(t, c.Config().Set(bspc.SettingFocusedBorderColor, "#FF0000"))
+		require.NoError(t, c.Config().Set(bspc.SettingAutomaticScheme, bspc.AutomaticSchemeTypeSpiral))
+		require.NoError(t, c.Config().Set(bspc.SettingPointerModifier, "mod4"))
+		require.NoError(t, c.Config().Set(bspc.SettingPointerAction1, bspc.PointerActionSettingTypeFocus))
+		require.NoError(t, c.Config().Desktop(bspc.ByName("I")).Set(bspc.SettingWindowGap, 12))
+		require.NoError(t, c.Config().Node(bspc.Selector{}).Set(bspc.SettingBorderWidth, 2))
+
+		assert.Equal(t, []string{
+			"config split_ratio 0.52",
+			"config focused_border_color #FF0000",
+			"config automatic_scheme spiral",
+			"config pointer_modifier mod4",
+			"config pointer_action1 focus",
+			"config --desktop I window_gap 12",
+			"config --node focused border_width 2",
+		}, srv.Commands())
+	})
+
+	global := []bspc.SettingScope{bspc.SettingScopeGlobal}
+
+	t.Run("should not send invalid values", func(t *testing.T) {
+		c, srv := newTestClient(t)
+
+		for _, err := range []error{
+			c.Config().Set(bspc.SettingWindowGap, "12"),
+			c.Config().Set(bspc.SettingFocusFollowsPointer, 1),
+			c.Config().Set(bspc.SettingNormalBorderColor, "#GG0000"),
+			c.Config().Set(bspc.SettingInitialPolarity, bspc.PolarityType("middle_child")),
+			c.Config().Set(bspc.SettingPointerModifier, nil),
+			c.Config().Set(bspc.SettingSplitRatio, 1.5),
+			c.Config().Set(bspc.SettingSplitRatio, 0.0),
+			c.Config().Set(bspc.Setting{Name: "made_up", Type: bspc.SettingTypeEnum, Scopes: global}, "on"),
+			c.Config().Monitor(bspc.Primary()).Set(bspc.SettingSplitRatio, 0.5),
+			c.Config().Node(bspc.Focused()).Set(bspc.SettingTopPadding, 10),
+		} {
+			assert.True(t, errors.Is(err, bspc.ErrInvalidArgument), err)
+		}
+
+		assert.Empty(t, srv.Commands())
+	})
+
+	t.Run("should parse the settings' values", func(t *testing.T) {
+		c, srv := newTestClient(t)
+		srv.Respond("config --monitor primary top_padding", "24\n")
+		srv.Respond("config gapless_monocle", "true\n")
+		srv.Respond("config split_ratio", "0.500000\n")
+		srv.Respond("config click_to_focus", "button1\n")
+		srv.Respond("config pointer_action3", "none\n")
+
+		padding, err := c.Config().Monitor(bspc.Primary()).Get(bspc.SettingTopPadding)
+		require.NoError(t, err)
+		assert.Equal(t, 24, padding)
+
+		gapless, err := c.Config().Get(bspc.SettingGaplessMonocle)
+		require.NoError(t, err)
+		assert.Equal(t, true, gapless)
+
+		ratio, err := c.Config().Get(bspc.SettingSplitRatio)
+		require.NoError(t, err)
+		assert.Equal(t, 0.5, ratio)
+
+		click, err := c.Config().Get(bspc.SettingClickToFocus)
+		require.NoError(t, err)
+		assert.Equal(t, bspc.ClickToFocusTypeButton1, click)
+
+		action, err := c.Config().Get(bspc.SettingPointerAction3)
+		require.NoError(t, err)
+		assert.Equal(t, bspc.PointerActionSettingTypeNone, action)
+	})
+
+	t.Run("should validate enums of settings that weren't taken from the registry", func(t *testing.T) {
+		c, srv := newTestClient(t)
+		srv.Respond("config initial_polarity", "first_child\n")
+		srv.Respond("config made_up", "on\n")
+
+		polarity := bspc.Setting{Name: "initial_polarity", Type: bspc.SettingTypeEnum, Scopes: global}
+		require.NoError(t, c.Config().Set(polarity, bspc.PolarityTypeSecondChild))
+
+		v, err := c.Config().Get(polarity)
+		require.NoError(t, err)
+		assert.Equal(t, bspc.PolarityTypeFirstChild, v)
+
+		_, err = c.Config().Get(bspc.Setting{Name: "made_up", Type: bspc.SettingTypeEnum, Scopes: global})
+		assert.True(t, errors.Is(err, bspc.ErrInvalidArgument), err)
+	})
+}
+
+func TestLookupSetting(t *testing.T) {
+	t.Run("should find every known setting by name", func(t *testing.T) {
+		for _, s := range bspc.Settings() {
+			found, ok := bspc.LookupSetting(s.Name)
+			require.True(t, ok, s.Name)
+			assert.Equal(t, s.Type, found.Type)
+		}
+
+		_, ok := bspc.LookupSetting("nonsense")
+		assert.False(t, ok)
+	})
+}
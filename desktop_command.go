@@ -0,0 +1,102 @@
+package bspc
+
+import (
+	"context"
+	"fmt"
+)
+
+// DesktopCommand builds and sends `desktop` commands for the desktops matching its selector.
+// Each method sends a single command to bspwm, and returns a *CommandError if bspwm rejects it.
+// Arguments are validated before being sent, in which case the returned error matches ErrInvalidArgument.
+type DesktopCommand struct {
+	client   client
+	ctx      context.Context
+	selector Selector
+}
+
+// Desktop returns a builder for the `desktop` commands, applied to the desktops matching the selector.
+// The zero selector targets the focused desktop.
+func (c client) Desktop(sel Selector) DesktopCommand {
+	return DesktopCommand{
+		client:   c,
+		ctx:      context.Background(),
+		selector: sel,
+	}
+}
+
+// WithContext returns a copy of the builder, that sends its commands under the given context.
+func (dc DesktopCommand) WithContext(ctx context.Context) DesktopCommand {
+	dc.ctx = ctx
+	return dc
+}
+
+// Focus focuses the desktop.
+func (dc DesktopCommand) Focus() error {
+	return dc.send("--focus")
+}
+
+// Activate activates the desktop, without focusing it.
+func (dc DesktopCommand) Activate() error {
+	return dc.send("--activate")
+}
+
+// ToMonitor sends the desktop to the monitor matching the selector.
+// If follow is true, the focus follows the desktop.
+func (dc DesktopCommand) ToMonitor(monitorSel Selector, follow bool) error {
+	return dc.send(withFollow([]string{"--to-monitor", monitorSel.String()}, follow)...)
+}
+
+// Swap swaps the desktop with the desktop matching the selector.
+func (dc DesktopCommand) Swap(desktopSel Selector) error {
+	return dc.send("--swap", desktopSel.String())
+}
+
+// Layout sets the layout of the desktop.
+func (dc DesktopCommand) Layout(layout LayoutType) error {
+	if !layout.IsValid() {
+		return fmt.Errorf("%w: invalid layout %s", ErrInvalidArgument, layout)
+	}
+
+	return dc.send("--layout", string(layout))
+}
+
+// CycleLayout cycles the layout of the desktop, in the given direction.
+func (dc DesktopCommand) CycleLayout(dir CycleDirType) error {
+	if !dir.IsValid() {
+		return fmt.Errorf("%w: invalid cycle direction %s", ErrInvalidArgument, dir)
+	}
+
+	return dc.send("--layout", string(dir))
+}
+
+// Rename renames the desktop.
+func (dc DesktopCommand) Rename(name string) error {
+	if name == "" {
+		return fmt.Errorf("%w: empty desktop name", ErrInvalidArgument)
+	}
+
+	return dc.send("--rename", name)
+}
+
+// Bubble moves the desktop, in the given direction, within its monitor.
+func (dc DesktopCommand) Bubble(dir CycleDirType) error {
+	if !dir.IsValid() {
+		return fmt.Errorf("%w: invalid cycle direction %s", ErrInvalidArgument, dir)
+	}
+
+	return dc.send("--bubble", string(dir))
+}
+
+// Remove removes the desktop.
+func (dc DesktopCommand) Remove() error {
+	return dc.send("--remove")
+}
+
+func (dc DesktopCommand) send(args ...string) error {
+	cmd := ipcCommand{"desktop"}
+	if !dc.selector.IsZero() {
+		cmd = append(cmd, dc.selector.String())
+	}
+
+	return dc.client.query(dc.ctx, append(cmd, args...), nil)
+}
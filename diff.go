@@ -0,0 +1,333 @@
+package bspc
+
+type (
+	// Change is a difference between two states, described by the event bspwm publishes for it.
+	// Its payload is one of the Event* payload types, so that it can be handled like any other event.
+	Change struct {
+		Type    EventType
+		Payload interface{}
+	}
+
+	// nodePlace is where a node sits in a state.
+	nodePlace struct {
+		monitorID ID
+		desktopID ID
+		node      *Node
+		// insertionPointID is the node's sibling, which is where bspwm would have inserted it.
+		insertionPointID ID
+	}
+
+	flagChange struct {
+		flag   FlagType
+		before bool
+		after  bool
+	}
+)
+
+// Event returns the change as an event, to be replayed through the same code that handles subscriptions.
+func (c Change) Event() Event {
+	return Event{Type: c.Type, Payload: c.Payload}
+}
+
+// DiffStates returns the changes that lead from the first state to the second one, as in two
+// `wm --dump-state` results. Monitors, desktops and nodes are matched by their IDs.
+// Changes come in an order that can be replayed: additions and transfers first, then property changes,
+// then removals, and focus changes last. Nodes are only reported as added, removed or transferred when they're
+// leaves, as bspwm creates and removes their parents on its own. Split changes are reported as EventTypeNodeSplit.
+func DiffStates(a, b State) []Change {
+	var (
+		additions = make([]Change, 0)
+		changes   = make([]Change, 0)
+		add       = func(t EventType, payload interface{}) {
+			additions = append(additions, Change{Type: t, Payload: payload})
+		}
+		change = func(t EventType, payload interface{}) {
+			changes = append(changes, Change{Type: t, Payload: payload})
+		}
+	)
+
+	aDesktops, bDesktops := indexDesktops(a), indexDesktops(b)
+	aNodes, bNodes := indexNodes(a), indexNodes(b)
+
+	for i := range b.Monitors {
+		bm := &b.Monitors[i]
+
+		am := a.FindMonitor(bm.ID)
+		if am == nil {
+			add(EventTypeMonitorAdd, EventMonitorAdd{MonitorID: bm.ID, MonitorName: bm.Name, MonitorGeometry: bm.Rectangle})
+			continue
+		}
+
+		if am.Name != bm.Name {
+			change(EventTypeMonitorRename, EventMonitorRename{MonitorID: bm.ID, MonitorOldName: am.Name, MonitorNewName: bm.Name})
+		}
+
+		if am.Rectangle != bm.Rectangle {
+			change(EventTypeMonitorGeometry, EventMonitorGeometry{MonitorID: bm.ID, MonitorGeometry: bm.Rectangle})
+		}
+	}
+
+	for _, bm := range b.Monitors {
+		for _, bd := range bm.Desktops {
+			aMonitorID, ok := aDesktops[bd.ID]
+			if !ok {
+				add(EventTypeDesktopAdd, EventDesktopAdd{MonitorID: bm.ID, DesktopID: bd.ID, DesktopName: bd.Name})
+				continue
+			}
+
+			if aMonitorID != bm.ID {
+				add(EventTypeDesktopTransfer, EventDesktopTransfer{
+					SourceMonitorID:      aMonitorID,
+					SourceDesktopID:      bd.ID,
+					DestinationMonitorID: bm.ID,
+					DestinationDesktopID: bd.ID,
+				})
+			}
+
+			ad := a.FindDesktop(bd.ID)
+			if ad.Name != bd.Name {
+				change(EventTypeDesktopRename, EventDesktopRename{
+					MonitorID:      bm.ID,
+					DesktopID:      bd.ID,
+					DesktopOldName: ad.Name,
+					DesktopNewName: bd.Name,
+				})
+			}
+
+			if ad.Layout != bd.Layout {
+				change(EventTypeDesktopLayout, EventDesktopLayout{MonitorID: bm.ID, DesktopID: bd.ID, DesktopLayout: bd.Layout})
+			}
+		}
+	}
+
+	for _, id := range orderedNodeIDs(b) {
+		bp := bNodes[id]
+
+		ap, ok := aNodes[id]
+		if !ok {
+			if isLeafNode(bp.node) {
+				add(EventTypeNodeAdd, EventNodeAdd{
+					MonitorID: bp.monitorID,
+					DesktopID: bp.desktopID,
+					IPID:      bp.insertionPointID,
+					NodeID:    id,
+				})
+			}
+
+			continue
+		}
+
+		if ap.desktopID != bp.desktopID && isLeafNode(bp.node) {
+			add(EventTypeNodeTransfer, EventNodeTransfer{
+				SourceMonitorID:      ap.monitorID,
+				SourceDesktopID:      ap.desktopID,
+				SourceNodeID:         id,
+				DestinationMonitorID: bp.monitorID,
+				DestinationDesktopID: bp.desktopID,
+				DestinationNodeID:    bp.insertionPointID,
+			})
+		}
+
+		diffNodes(ap, bp, change)
+	}
+
+	// Property changes may refer to what was just added or transferred, so they're replayed afterwards.
+	changes = append(additions, changes...)
+
+	for _, id := range orderedNodeIDs(a) {
+		ap := aNodes[id]
+		if _, ok := bNodes[id]; !ok && isLeafNode(ap.node) {
+			change(EventTypeNodeRemove, EventNodeRemove{MonitorID: ap.monitorID, DesktopID: ap.desktopID, NodeID: id})
+		}
+	}
+
+	for _, am := range a.Monitors {
+		for _, ad := range am.Desktops {
+			if _, ok := bDesktops[ad.ID]; !ok {
+				change(EventTypeDesktopRemove, EventDesktopRemove{MonitorID: am.ID, DesktopID: ad.ID})
+			}
+		}
+	}
+
+	for _, am := range a.Monitors {
+		if b.FindMonitor(am.ID) == nil {
+			change(EventTypeMonitorRemove, EventMonitorRemove{MonitorID: am.ID})
+		}
+	}
+
+	diffFocus(a, b, change)
+
+	return changes
+}
+
+// diffNodes adds the changes to the properties of a node that exists in both states.
+func diffNodes(ap, bp nodePlace, add func(EventType, interface{})) {
+	an, bn := ap.node, bp.node
+
+	if !isLeafNode(bn) && !isLeafNode(an) && (an.SplitType != bn.SplitType || an.SplitRatio != bn.SplitRatio) {
+		add(EventTypeNodeSplit, EventNodeSplit{
+			MonitorID:  bp.monitorID,
+			DesktopID:  bp.desktopID,
+			NodeID:     bn.ID,
+			SplitType:  bn.SplitType,
+			SplitRatio: bn.SplitRatio,
+		})
+	}
+
+	flags := []flagChange{
+		{FlagTypeHidden, an.Hidden, bn.Hidden},
+		{FlagTypeSticky, an.Sticky, bn.Sticky},
+		{FlagTypePrivate, an.Private, bn.Private},
+		{FlagTypeLocked, an.Locked, bn.Locked},
+		{FlagTypeMarked, an.Marked, bn.Marked},
+	}
+
+	if an.Client != nil && bn.Client != nil {
+		flags = append(flags, flagChange{FlagTypeUrgent, an.Client.Urgent, bn.Client.Urgent})
+	}
+
+	for _, f := range flags {
+		if f.before != f.after {
+			add(EventTypeNodeFlag, EventNodeFlag{
+				MonitorID:  bp.monitorID,
+				DesktopID:  bp.desktopID,
+				NodeID:     bn.ID,
+				Flag:       f.flag,
+				WasEnabled: f.after,
+			})
+		}
+	}
+
+	if an.Client == nil || bn.Client == nil {
+		return
+	}
+
+	ac, bc := an.Client, bn.Client
+
+	if ac.State != bc.State {
+		add(EventTypeNodeState, EventNodeState{
+			MonitorID:  bp.monitorID,
+			DesktopID:  bp.desktopID,
+			NodeID:     bn.ID,
+			State:      bc.State,
+			WasEnabled: true,
+		})
+	}
+
+	if ac.Layer != bc.Layer {
+		add(EventTypeNodeLayer, EventNodeLayer{MonitorID: bp.monitorID, DesktopID: bp.desktopID, NodeID: bn.ID, Layer: bc.Layer})
+	}
+
+	if before, after := windowRectangle(ac), windowRectangle(bc); before != after {
+		add(EventTypeNodeGeometry, EventNodeGeometry{MonitorID: bp.monitorID, DesktopID: bp.desktopID, NodeID: bn.ID, NodeGeometry: after})
+	}
+}
+
+// diffFocus adds the changes to the focused and active monitors, desktops and nodes.
+// Desktops and nodes that are focused in the second state are reported as focused, others as activated.
+func diffFocus(a, b State, add func(EventType, interface{})) {
+	if a.FocusedMonitorID != b.FocusedMonitorID {
+		add(EventTypeMonitorFocus, EventMonitorFocus{MonitorID: b.FocusedMonitorID})
+	}
+
+	for _, bm := range b.Monitors {
+		am := a.FindMonitor(bm.ID)
+
+		if bm.FocusedDesktopID != NilID && (am == nil || am.FocusedDesktopID != bm.FocusedDesktopID) {
+			payload := EventDesktopFocus{MonitorID: bm.ID, DesktopID: bm.FocusedDesktopID}
+			if bm.ID == b.FocusedMonitorID {
+				add(EventTypeDesktopFocus, payload)
+			} else {
+				add(EventTypeDesktopActivate, EventDesktopActivate(payload))
+			}
+		}
+
+		for _, bd := range bm.Desktops {
+			ad := a.FindDesktop(bd.ID)
+			if bd.FocusedNodeID == NilID || (ad != nil && ad.FocusedNodeID == bd.FocusedNodeID) {
+				continue
+			}
+
+			payload := EventNodeFocus{MonitorID: bm.ID, DesktopID: bd.ID, NodeID: bd.FocusedNodeID}
+			if bm.ID == b.FocusedMonitorID && bd.ID == bm.FocusedDesktopID {
+				add(EventTypeNodeFocus, payload)
+			} else {
+				add(EventTypeNodeActivate, EventNodeActivate(payload))
+			}
+		}
+	}
+}
+
+// indexDesktops maps the ID of each desktop to the ID of its monitor.
+func indexDesktops(s State) map[ID]ID {
+	index := make(map[ID]ID)
+	for _, m := range s.Monitors {
+		for _, d := range m.Desktops {
+			index[d.ID] = m.ID
+		}
+	}
+
+	return index
+}
+
+func indexNodes(s State) map[ID]nodePlace {
+	index := make(map[ID]nodePlace)
+	for i := range s.Monitors {
+		m := &s.Monitors[i]
+		for j := range m.Desktops {
+			d := &m.Desktops[j]
+			if d.Root.ID == NilID {
+				continue
+			}
+
+			indexTree(&d.Root, NilID, func(n *Node, siblingID ID) {
+				index[n.ID] = nodePlace{monitorID: m.ID, desktopID: d.ID, node: n, insertionPointID: siblingID}
+			})
+		}
+	}
+
+	return index
+}
+
+func indexTree(n *Node, siblingID ID, fn func(n *Node, siblingID ID)) {
+	fn(n, siblingID)
+
+	if n.FirstChild != nil && n.SecondChild != nil {
+		indexTree(n.FirstChild, n.SecondChild.ID, fn)
+		indexTree(n.SecondChild, n.FirstChild.ID, fn)
+	}
+}
+
+// orderedNodeIDs returns the IDs of every node in the state, monitor by monitor, desktop by desktop, in pre-order.
+func orderedNodeIDs(s State) []ID {
+	var ids []ID
+	for i := range s.Monitors {
+		for j := range s.Monitors[i].Desktops {
+			root := &s.Monitors[i].Desktops[j].Root
+			if root.ID == NilID {
+				continue
+			}
+
+			root.Walk(WalkOrderTypePre, func(n *Node) bool {
+				ids = append(ids, n.ID)
+				return true
+			})
+		}
+	}
+
+	return ids
+}
+
+// isLeafNode returns true for windows and receptacles.
+func isLeafNode(n *Node) bool {
+	return n.FirstChild == nil && n.SecondChild == nil
+}
+
+// windowRectangle returns the geometry of the client's window, which depends on its state.
+func windowRectangle(c *NodeClient) Rectangle {
+	if c.State == StateTypeFloating {
+		return c.FloatingRectangle
+	}
+
+	return c.TiledRectangle
+}
@@ -0,0 +1,123 @@
+package bspc_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/diogox/bspc-go"
+)
+
+func TestDiffStates(t *testing.T) {
+	const (
+		monitorID = bspc.ID(0x00200002)
+		desktopI  = bspc.ID(0x00200003)
+		desktopII = bspc.ID(0x00200004)
+		parentID  = bspc.ID(0x00400001)
+		firstID   = bspc.ID(0x00E00003)
+		secondID  = bspc.ID(0x00E00004)
+		thirdID   = bspc.ID(0x00E00005)
+	)
+
+	leaf := func(id bspc.ID, state bspc.StateType) *bspc.Node {
+		return &bspc.Node{ID: id, Client: &bspc.NodeClient{State: state, Layer: bspc.LayerTypeNormal}}
+	}
+
+	before := bspc.State{
+		FocusedMonitorID: monitorID,
+		Monitors: []bspc.Monitor{{
+			ID:               monitorID,
+			Name:             "eDP-1",
+			FocusedDesktopID: desktopI,
+			Desktops: []bspc.Desktop{
+				{
+					ID:            desktopI,
+					Name:          "I",
+					Layout:        bspc.LayoutTypeTiled,
+					FocusedNodeID: firstID,
+					Root: bspc.Node{
+						ID:          parentID,
+						SplitType:   bspc.SplitTypeVertical,
+						SplitRatio:  0.5,
+						FirstChild:  leaf(firstID, bspc.StateTypeTiled),
+						SecondChild: leaf(secondID, bspc.StateTypeTiled),
+					},
+				},
+				{ID: desktopII, Name: "II", Layout: bspc.LayoutTypeTiled},
+			},
+		}},
+	}
+
+	t.Run("should report nothing for equal states", func(t *testing.T) {
+		assert.Empty(t, bspc.DiffStates(before, before))
+	})
+
+	t.Run("should report the changes as events, in a replayable order", func(t *testing.T) {
+		after := bspc.State{
+			FocusedMonitorID: monitorID,
+			Monitors: []bspc.Monitor{{
+				ID:               monitorID,
+				Name:             "eDP-1",
+				FocusedDesktopID: desktopII,
+				Desktops: []bspc.Desktop{
+					{
+						ID:     desktopI,
+						Name:   "I",
+						Layout: bspc.LayoutTypeMonocle,
+						Root: bspc.Node{
+							ID:          parentID,
+							SplitType:   bspc.SplitTypeHorizontal,
+							SplitRatio:  0.3,
+							FirstChild:  leaf(firstID, bspc.StateTypeFloating),
+							SecondChild: leaf(thirdID, bspc.StateTypeTiled),
+						},
+					},
+					{
+						ID:            desktopII,
+						Name:          "II",
+						Layout:        bspc.LayoutTypeTiled,
+						FocusedNodeID: secondID,
+						Root:          *leaf(secondID, bspc.StateTypeTiled),
+					},
+				},
+			}},
+		}
+		after.Monitors[0].Desktops[1].Root.Sticky = true
+
+		changes := bspc.DiffStates(before, after)
+
+		assert.Equal(t, []bspc.Change{
+			{Type: bspc.EventTypeNodeAdd, Payload: bspc.EventNodeAdd{MonitorID: monitorID, DesktopID: desktopI, IPID: firstID, NodeID: thirdID}},
+			{Type: bspc.EventTypeNodeTransfer, Payload: bspc.EventNodeTransfer{
+				SourceMonitorID: monitorID, SourceDesktopID: desktopI, SourceNodeID: secondID,
+				DestinationMonitorID: monitorID, DestinationDesktopID: desktopII,
+			}},
+			{Type: bspc.EventTypeDesktopLayout, Payload: bspc.EventDesktopLayout{MonitorID: monitorID, DesktopID: desktopI, DesktopLayout: bspc.LayoutTypeMonocle}},
+			{Type: bspc.EventTypeNodeSplit, Payload: bspc.EventNodeSplit{
+				MonitorID: monitorID, DesktopID: desktopI, NodeID: parentID, SplitType: bspc.SplitTypeHorizontal, SplitRatio: 0.3,
+			}},
+			{Type: bspc.EventTypeNodeState, Payload: bspc.EventNodeState{
+				MonitorID: monitorID, DesktopID: desktopI, NodeID: firstID, State: bspc.StateTypeFloating, WasEnabled: true,
+			}},
+			{Type: bspc.EventTypeNodeFlag, Payload: bspc.EventNodeFlag{
+				MonitorID: monitorID, DesktopID: desktopII, NodeID: secondID, Flag: bspc.FlagTypeSticky, WasEnabled: true,
+			}},
+			{Type: bspc.EventTypeDesktopFocus, Payload: bspc.EventDesktopFocus{MonitorID: monitorID, DesktopID: desktopII}},
+			{Type: bspc.EventTypeNodeFocus, Payload: bspc.EventNodeFocus{MonitorID: monitorID, DesktopID: desktopII, NodeID: secondID}},
+		}, changes)
+
+		assert.Equal(t, bspc.Event{Type: changes[0].Type, Payload: changes[0].Payload}, changes[0].Event())
+	})
+
+	t.Run("should report removals", func(t *testing.T) {
+		after := bspc.State{FocusedMonitorID: monitorID}
+
+		assert.Equal(t, []bspc.Change{
+			{Type: bspc.EventTypeNodeRemove, Payload: bspc.EventNodeRemove{MonitorID: monitorID, DesktopID: desktopI, NodeID: firstID}},
+			{Type: bspc.EventTypeNodeRemove, Payload: bspc.EventNodeRemove{MonitorID: monitorID, DesktopID: desktopI, NodeID: secondID}},
+			{Type: bspc.EventTypeDesktopRemove, Payload: bspc.EventDesktopRemove{MonitorID: monitorID, DesktopID: desktopI}},
+			{Type: bspc.EventTypeDesktopRemove, Payload: bspc.EventDesktopRemove{MonitorID: monitorID, DesktopID: desktopII}},
+			{Type: bspc.EventTypeMonitorRemove, Payload: bspc.EventMonitorRemove{MonitorID: monitorID}},
+		}, bspc.DiffStates(before, after))
+	})
+}
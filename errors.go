@@ -0,0 +1,77 @@
+package bspc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// failureMessage is the byte bspwm prefixes its response with, when a command fails.
+const failureMessage = '\a'
+
+var (
+	// ErrInvalidSelector is matched by command errors caused by a selector bspwm could not resolve.
+	ErrInvalidSelector = errors.New("invalid selector")
+	// ErrUnknownCommand is matched by command errors caused by a domain or command bspwm does not know.
+	ErrUnknownCommand = errors.New("unknown command")
+	// ErrInvalidArgument is matched by command errors caused by missing or malformed arguments.
+	ErrInvalidArgument = errors.New("invalid argument")
+)
+
+// CommandError is returned when bspwm reports that a command has failed.
+// It can be compared against the sentinel errors in this package with errors.Is.
+type CommandError struct {
+	// Command is the raw command that was sent to bspwm.
+	Command string
+	// Message is the error message bspwm responded with. It can be empty, as bspwm doesn't always explain itself.
+	Message string
+}
+
+func newCommandError(rawCmd string, res []byte) *CommandError {
+	return &CommandError{
+		Command: rawCmd,
+		Message: strings.TrimSpace(string(res)),
+	}
+}
+
+func (e *CommandError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("bspwm command '%s' failed", e.Command)
+	}
+
+	return fmt.Sprintf("bspwm command '%s' failed: %s", e.Command, e.Message)
+}
+
+// errorPatterns maps the sentinel errors to the messages bspwm fails commands with, as found in its messages.c.
+// Those messages are prefixed with the domain and command they come from, as in: "node -t: Invalid argument: 'nope'."
+var errorPatterns = map[error][]string{
+	ErrInvalidSelector: {
+		"Invalid descriptor found in",
+		"Invalid modifier found in",
+	},
+	ErrUnknownCommand: {
+		"Unknown domain or command:",
+		"Unknown command:",
+	},
+	ErrInvalidArgument: {
+		"Invalid argument:",
+		"Invalid value:",
+		"Unknown setting:",
+		"No argument given.",
+		"No arguments given.",
+		"Not enough arguments.",
+		"Missing arguments.",
+	},
+}
+
+// Is reports whether the error falls under one of the sentinel errors in this package,
+// based on the message bspwm responded with.
+func (e *CommandError) Is(target error) bool {
+	for _, p := range errorPatterns[target] {
+		if strings.Contains(e.Message, p) {
+			return true
+		}
+	}
+
+	return false
+}
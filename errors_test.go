@@ -0,0 +1,89 @@
+package bspc_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/diogox/bspc-go"
+)
+
+func TestCommandError_Is(t *testing.T) {
+	tests := map[string]struct {
+		message string
+		want    error
+	}{
+		"should match invalid descriptors as invalid selectors": {
+			message: "node: Invalid descriptor found in 'nonsense'.",
+			want:    bspc.ErrInvalidSelector,
+		},
+		"should match invalid modifiers as invalid selectors": {
+			message: "desktop: Invalid modifier found in 'focused.nope'.",
+			want:    bspc.ErrInvalidSelector,
+		},
+		"should match unknown domains as unknown commands": {
+			message: "Unknown domain or command: 'nope'.",
+			want:    bspc.ErrUnknownCommand,
+		},
+		"should match unknown node commands as unknown commands": {
+			message: "node: Unknown command: '--nope'.",
+			want:    bspc.ErrUnknownCommand,
+		},
+		"should match invalid arguments": {
+			message: "node -t: Invalid argument: 'nope'.",
+			want:    bspc.ErrInvalidArgument,
+		},
+		"should match invalid values as invalid arguments": {
+			message: "config: border_width: Invalid value: 'wide'.",
+			want:    bspc.ErrInvalidArgument,
+		},
+		"should match unknown settings as invalid arguments": {
+			message: "config: Unknown setting: 'nope'.",
+			want:    bspc.ErrInvalidArgument,
+		},
+		"should match missing arguments as invalid arguments": {
+			message: "node -d: No argument given.",
+			want:    bspc.ErrInvalidArgument,
+		},
+		"should match too few arguments as invalid arguments": {
+			message: "node -z: Not enough arguments.",
+			want:    bspc.ErrInvalidArgument,
+		},
+		"should match missing config arguments as invalid arguments": {
+			message: "config: Missing arguments.",
+			want:    bspc.ErrInvalidArgument,
+		},
+		"should match commands without arguments as invalid arguments": {
+			message: "No arguments given.",
+			want:    bspc.ErrInvalidArgument,
+		},
+	}
+
+	sentinels := []error{bspc.ErrInvalidSelector, bspc.ErrUnknownCommand, bspc.ErrInvalidArgument}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			err := fmt.Errorf("wrapped: %w", &bspc.CommandError{Command: "some command", Message: tt.message})
+
+			for _, sentinel := range sentinels {
+				assert.Equal(t, sentinel == tt.want, errors.Is(err, sentinel), sentinel.Error())
+			}
+
+			var cmdErr *bspc.CommandError
+			assert.True(t, errors.As(err, &cmdErr))
+			assert.Equal(t, tt.message, cmdErr.Message)
+		})
+	}
+
+	t.Run("should not match any sentinel when bspwm gives no reason", func(t *testing.T) {
+		err := &bspc.CommandError{Command: "node -f west"}
+
+		for _, sentinel := range sentinels {
+			assert.False(t, errors.Is(err, sentinel))
+		}
+		assert.Equal(t, "bspwm command 'node -f west' failed", err.Error())
+	})
+}
@@ -3,8 +3,11 @@ package bspc
 type EventType string
 
 const (
-	// TODO: Uncomment this when "report" events are supported.
-	// EventTypeAll EventType = "all".
+	EventTypeAll EventType = "all"
+
+	// Report.
+	// Its payload is a ReportStatus.
+	EventTypeReport EventType = "report"
 
 	// Monitor
 	// "Please note that bspwm initializes monitors before
@@ -43,6 +46,11 @@ const (
 
 	// Pointer.
 	EventTypePointerAction EventType = "pointer_action"
+
+	// Synthetic.
+	// These are never published by bspwm, only by this package.
+	EventTypeReconnected EventType = "reconnected"
+	EventTypeNodeSplit   EventType = "node_split"
 )
 
 type (
@@ -50,7 +58,7 @@ type (
 	EventMonitorAdd struct {
 		MonitorID       ID
 		MonitorName     string
-		MonitorGeometry rectangle
+		MonitorGeometry Rectangle
 	}
 	EventMonitorRename struct {
 		MonitorID      ID
@@ -69,7 +77,7 @@ type (
 	}
 	EventMonitorGeometry struct {
 		MonitorID       ID
-		MonitorGeometry rectangle
+		MonitorGeometry Rectangle
 	}
 
 	// Desktop.
@@ -158,7 +166,7 @@ type (
 		NodeID    ID
 
 		// Only one of the below will be available.
-		SplitDirection *SplitType
+		SplitDirection *DirectionType
 		SplitRatio     *float64
 		IsCancel       *bool
 	}
@@ -171,7 +179,7 @@ type (
 		MonitorID    ID
 		DesktopID    ID
 		NodeID       ID
-		NodeGeometry rectangle
+		NodeGeometry Rectangle
 	}
 	EventNodeState struct {
 		MonitorID  ID
@@ -205,4 +213,18 @@ type (
 		PointerAction      PointerActionType
 		PointerActionState PointerActionStateType
 	}
+
+	// Synthetic.
+	EventReconnected struct {
+		// Attempts is the number of connection attempts it took to subscribe again.
+		Attempts int
+	}
+	// EventNodeSplit is only reported by DiffStates, as bspwm has no event for split changes.
+	EventNodeSplit struct {
+		MonitorID  ID
+		DesktopID  ID
+		NodeID     ID
+		SplitType  SplitType
+		SplitRatio float64
+	}
 )
@@ -0,0 +1,11 @@
+package bspc
+
+import "testing"
+
+// SetSocketDir makes the socket lookups search the directory instead of /tmp, until the test finishes.
+func SetSocketDir(t *testing.T, dir string) {
+	previous := socketDir
+	socketDir = dir
+
+	t.Cleanup(func() { socketDir = previous })
+}
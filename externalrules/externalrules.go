@@ -0,0 +1,84 @@
+// Package externalrules helps writing programs for bspwm's external_rules_command setting.
+//
+// bspwm runs that program whenever it's about to manage a window, passing in the window's ID, class and
+// instance, along with the consequences its own rules already decided. The consequences the program prints
+// are then applied on top of those.
+// Example usage:
+//
+//	func main() {
+//		externalrules.Main(externalrules.RuleSet{
+//			{Match: externalrules.Class("^(?i)firefox$"), Consequences: bspc.RuleConsequences{Desktop: bspc.Index(2)}},
+//		}.Handle)
+//	}
+package externalrules
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/diogox/bspc-go"
+)
+
+type (
+	// Window is the window bspwm is about to manage.
+	Window struct {
+		ID       bspc.ID
+		Class    string
+		Instance string
+		// Consequences are the ones bspwm already decided, from its own rules.
+		Consequences bspc.RuleConsequences
+	}
+
+	// Handler returns the consequences to apply to the window, on top of the ones bspwm already decided.
+	Handler func(w Window) bspc.RuleConsequences
+)
+
+// Main runs the handler with the program's arguments, and prints its consequences.
+// It exits with a non-zero status if the arguments aren't the ones bspwm passes in.
+func Main(h Handler) {
+	if err := Run(os.Args[1:], os.Stdout, h); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// Run parses the arguments passed in by bspwm, and writes the handler's consequences into the writer.
+func Run(args []string, w io.Writer, h Handler) error {
+	win, err := ParseArgs(args)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintln(w, h(win).String()); err != nil {
+		return fmt.Errorf("failed to write consequences: %w", err)
+	}
+
+	return nil
+}
+
+// ParseArgs parses the arguments bspwm runs the external rules command with:
+// the window's ID, class and instance, followed by its current consequences.
+func ParseArgs(args []string) (Window, error) {
+	if len(args) < 3 {
+		return Window{}, fmt.Errorf("expected at least 3 arguments, got %d", len(args))
+	}
+
+	id, err := bspc.ParseID(args[0])
+	if err != nil {
+		return Window{}, fmt.Errorf("invalid window ID: %w", err)
+	}
+
+	consequences, err := bspc.ParseRuleConsequences(strings.Join(args[3:], " "))
+	if err != nil {
+		return Window{}, fmt.Errorf("invalid consequences: %w", err)
+	}
+
+	return Window{
+		ID:           id,
+		Class:        args[1],
+		Instance:     args[2],
+		Consequences: consequences,
+	}, nil
+}
@@ -0,0 +1,72 @@
+package externalrules_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diogox/bspc-go"
+	"github.com/diogox/bspc-go/bspctest"
+	"github.com/diogox/bspc-go/externalrules"
+)
+
+// consequences is how bspwm passes them in: every one of them, with the unset ones left empty.
+const consequences = "monitor= desktop= node= state=floating layer= honor_size_hints=false split_dir= split_ratio=0.000000 " +
+	"hidden=off sticky=off private=off locked=off marked=off center=on follow=off manage=on focus=on border=on rectangle="
+
+func TestRun(t *testing.T) {
+	t.Run("should pass the window into the handler and print its consequences", func(t *testing.T) {
+		var got externalrules.Window
+
+		var out bytes.Buffer
+		err := externalrules.Run([]string{"14680067", "mpv", "gl", consequences}, &out, func(w externalrules.Window) bspc.RuleConsequences {
+			got = w
+			return bspc.RuleConsequences{Desktop: bspc.ByName("media"), Sticky: bspc.ToggleTypeOn}
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, bspc.ID(0x00E00003), got.ID)
+		assert.Equal(t, "mpv", got.Class)
+		assert.Equal(t, "gl", got.Instance)
+		assert.Equal(t, bspc.StateTypeFloating, got.Consequences.State)
+		assert.Equal(t, bspc.ToggleTypeOn, got.Consequences.Center)
+		assert.Equal(t, "desktop=media sticky=on\n", out.String())
+	})
+
+	t.Run("should fail on missing arguments", func(t *testing.T) {
+		err := externalrules.Run([]string{"14680067"}, &bytes.Buffer{}, externalrules.RuleSet{}.Handle)
+		assert.Error(t, err)
+	})
+}
+
+func TestRuleSet(t *testing.T) {
+	srv := bspctest.NewServer(t)
+	srv.Respond("query --desktops --desktop focused --names", "code\n")
+
+	c, err := bspc.NewWithSocketPath(srv.SocketPath(), nil)
+	require.NoError(t, err)
+
+	rules := externalrules.RuleSet{
+		{
+			Match:        externalrules.All(externalrules.Class("^(?i)firefox$"), externalrules.Not(externalrules.Instance("Dialog"))),
+			Consequences: bspc.RuleConsequences{Desktop: bspc.Index(2)},
+		},
+		{
+			Match:        externalrules.All(externalrules.Class("Alacritty"), externalrules.OnDesktop(c, "code")),
+			Consequences: bspc.RuleConsequences{SplitDir: bspc.DirectionTypeRight},
+		},
+		{
+			Match:        externalrules.Any(externalrules.Class("Alacritty"), externalrules.Instance("Dialog")),
+			Consequences: bspc.RuleConsequences{State: bspc.StateTypeFloating},
+		},
+	}
+
+	t.Run("should apply the first matching rule", func(t *testing.T) {
+		assert.Equal(t, bspc.Index(2), rules.Handle(externalrules.Window{Class: "Firefox", Instance: "Navigator"}).Desktop)
+		assert.Equal(t, bspc.DirectionTypeRight, rules.Handle(externalrules.Window{Class: "Alacritty"}).SplitDir)
+		assert.Equal(t, bspc.StateTypeFloating, rules.Handle(externalrules.Window{Class: "firefox", Instance: "Dialog"}).State)
+		assert.Equal(t, bspc.RuleConsequences{}, rules.Handle(externalrules.Window{Class: "mpv"}))
+	})
+}
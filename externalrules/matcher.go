@@ -0,0 +1,95 @@
+package externalrules
+
+import (
+	"regexp"
+
+	"github.com/diogox/bspc-go"
+)
+
+type (
+	// Matcher returns true if the rule it belongs to should be applied to the window.
+	Matcher func(w Window) bool
+
+	// Rule applies its consequences to the windows it matches.
+	Rule struct {
+		Match        Matcher
+		Consequences bspc.RuleConsequences
+	}
+
+	// RuleSet declares the consequences of multiple rules. Its Handle method is a Handler.
+	RuleSet []Rule
+)
+
+// Handle returns the consequences of the first rule matching the window, or no consequences if none does.
+func (rs RuleSet) Handle(w Window) bspc.RuleConsequences {
+	for _, r := range rs {
+		if r.Match == nil || r.Match(w) {
+			return r.Consequences
+		}
+	}
+
+	return bspc.RuleConsequences{}
+}
+
+// Class matches the windows whose class matches the regular expression.
+// It panics if the expression doesn't compile.
+func Class(expr string) Matcher {
+	re := regexp.MustCompile(expr)
+
+	return func(w Window) bool {
+		return re.MatchString(w.Class)
+	}
+}
+
+// Instance matches the windows with the given instance.
+func Instance(instance string) Matcher {
+	return func(w Window) bool {
+		return w.Instance == instance
+	}
+}
+
+// OnDesktop matches the windows managed while the focused desktop has the given name.
+// Nothing is matched if the client fails to query it.
+func OnDesktop(c bspc.Client, name string) Matcher {
+	return func(Window) bool {
+		names, err := c.QueryDesktopNames(bspc.Focused())
+		if err != nil || len(names) == 0 {
+			return false
+		}
+
+		return names[0] == name
+	}
+}
+
+// All matches the windows matched by every one of the matchers.
+func All(matchers ...Matcher) Matcher {
+	return func(w Window) bool {
+		for _, m := range matchers {
+			if !m(w) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// Any matches the windows matched by at least one of the matchers.
+func Any(matchers ...Matcher) Matcher {
+	return func(w Window) bool {
+		for _, m := range matchers {
+			if m(w) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// Not matches the windows the matcher doesn't.
+func Not(m Matcher) Matcher {
+	return func(w Window) bool {
+		return !m(w)
+	}
+}
@@ -0,0 +1,187 @@
+package bspc
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+type (
+	// Point is a position on the screen, in pixels.
+	Point struct {
+		X int
+		Y int
+	}
+
+	// Rectangle is an area on the screen, in pixels. Its position is the one of its top left corner.
+	Rectangle struct {
+		X      int `json:"x"`
+		Y      int `json:"y"`
+		Width  int `json:"width"`
+		Height int `json:"height"`
+	}
+
+	// Padding is the space left around the edges of a rectangle.
+	Padding struct {
+		Top    int `json:"top"`
+		Right  int `json:"right"`
+		Bottom int `json:"bottom"`
+		Left   int `json:"left"`
+	}
+
+	// Constraints are the minimum dimensions of a node's tiled rectangle.
+	Constraints struct {
+		MinWidth  int `json:"min_width"`
+		MinHeight int `json:"min_height"`
+	}
+)
+
+// geometryRegex matches the geometry in bspwm's format: WxH+X+Y, where the position can also be negative.
+var geometryRegex = regexp.MustCompile(`^(\d+)x(\d+)([+-]\d+)([+-]\d+)$`)
+
+// ParseRectangle parses a rectangle in bspwm's WxH+X+Y format, as found in its events, commands and rules.
+func ParseRectangle(geometry string) (Rectangle, error) {
+	matches := geometryRegex.FindStringSubmatch(geometry)
+	if matches == nil {
+		return Rectangle{}, fmt.Errorf("invalid geometry: %s", geometry)
+	}
+
+	// The regex already guarantees these are numbers, so only overflows can fail.
+	values := make([]int, 0, 4)
+	for _, m := range matches[1:] {
+		v, err := strconv.Atoi(m)
+		if err != nil {
+			return Rectangle{}, fmt.Errorf("invalid geometry %s: %v", geometry, err)
+		}
+
+		values = append(values, v)
+	}
+
+	return Rectangle{
+		Width:  values[0],
+		Height: values[1],
+		X:      values[2],
+		Y:      values[3],
+	}, nil
+}
+
+// String returns the rectangle in bspwm's WxH+X+Y format.
+func (r Rectangle) String() string {
+	return fmt.Sprintf("%dx%d%+d%+d", r.Width, r.Height, r.X, r.Y)
+}
+
+// IsEmpty returns true if the rectangle has no area.
+func (r Rectangle) IsEmpty() bool {
+	return r.Width <= 0 || r.Height <= 0
+}
+
+// Area returns the area of the rectangle, in pixels.
+func (r Rectangle) Area() int {
+	if r.IsEmpty() {
+		return 0
+	}
+
+	return r.Width * r.Height
+}
+
+// Center returns the point in the middle of the rectangle, rounded towards its top left corner.
+func (r Rectangle) Center() Point {
+	return Point{X: r.X + r.Width/2, Y: r.Y + r.Height/2}
+}
+
+// Contains returns true if the point is inside the rectangle. Its right and bottom edges are excluded.
+func (r Rectangle) Contains(p Point) bool {
+	return p.X >= r.X && p.X < r.X+r.Width &&
+		p.Y >= r.Y && p.Y < r.Y+r.Height
+}
+
+// Intersect returns the area covered by both rectangles, or the zero rectangle if they don't overlap.
+func (r Rectangle) Intersect(o Rectangle) Rectangle {
+	x1, y1 := maxInt(r.X, o.X), maxInt(r.Y, o.Y)
+	x2, y2 := minInt(r.X+r.Width, o.X+o.Width), minInt(r.Y+r.Height, o.Y+o.Height)
+
+	if x2 <= x1 || y2 <= y1 {
+		return Rectangle{}
+	}
+
+	return Rectangle{X: x1, Y: y1, Width: x2 - x1, Height: y2 - y1}
+}
+
+// Union returns the smallest rectangle covering both rectangles. Empty rectangles are ignored.
+func (r Rectangle) Union(o Rectangle) Rectangle {
+	if r.IsEmpty() {
+		return o
+	}
+
+	if o.IsEmpty() {
+		return r
+	}
+
+	x1, y1 := minInt(r.X, o.X), minInt(r.Y, o.Y)
+	x2, y2 := maxInt(r.X+r.Width, o.X+o.Width), maxInt(r.Y+r.Height, o.Y+o.Height)
+
+	return Rectangle{X: x1, Y: y1, Width: x2 - x1, Height: y2 - y1}
+}
+
+// Inset returns the rectangle left once the padding is removed from its edges.
+// Its dimensions never go below zero.
+func (r Rectangle) Inset(p Padding) Rectangle {
+	return Rectangle{
+		X:      r.X + p.Left,
+		Y:      r.Y + p.Top,
+		Width:  maxInt(r.Width-p.Left-p.Right, 0),
+		Height: maxInt(r.Height-p.Top-p.Bottom, 0),
+	}
+}
+
+// Split splits the rectangle the way bspwm splits a node's rectangle between its children.
+// A vertical split places the first rectangle on the left, and a horizontal one places it on top.
+// The ratio is the share of the first rectangle, and its dimension is truncated, as bspwm does.
+func (r Rectangle) Split(split SplitType, ratio float64) (first, second Rectangle) {
+	first, second = r, r
+
+	if split == SplitTypeVertical {
+		fence := int(float64(r.Width) * ratio)
+		first.Width = fence
+		second.X += fence
+		second.Width -= fence
+	} else {
+		fence := int(float64(r.Height) * ratio)
+		first.Height = fence
+		second.Y += fence
+		second.Height -= fence
+	}
+
+	return first, second
+}
+
+// IsZero returns true if there's no padding on any edge.
+func (p Padding) IsZero() bool {
+	return p == Padding{}
+}
+
+// Add returns the sum of both paddings, edge by edge.
+func (p Padding) Add(o Padding) Padding {
+	return Padding{
+		Top:    p.Top + o.Top,
+		Right:  p.Right + o.Right,
+		Bottom: p.Bottom + o.Bottom,
+		Left:   p.Left + o.Left,
+	}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
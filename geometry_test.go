@@ -0,0 +1,73 @@
+package bspc_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diogox/bspc-go"
+)
+
+func TestParseRectangle(t *testing.T) {
+	t.Run("should parse what String returns", func(t *testing.T) {
+		for _, geometry := range []string{"1920x1080+0+0", "1280x1024-1280+56", "0x0+0-20"} {
+			r, err := bspc.ParseRectangle(geometry)
+			require.NoError(t, err, geometry)
+
+			assert.Equal(t, geometry, r.String())
+		}
+	})
+
+	t.Run("should fail on invalid geometries", func(t *testing.T) {
+		for _, geometry := range []string{"", "1920x1080", "1920+0+0", "1920x1080+0+0+0", "ax1080+0+0", "-1920x1080+0+0"} {
+			_, err := bspc.ParseRectangle(geometry)
+			assert.Error(t, err, geometry)
+		}
+	})
+}
+
+func TestRectangle(t *testing.T) {
+	r := bspc.Rectangle{X: 100, Y: 50, Width: 200, Height: 100}
+
+	t.Run("should survive a JSON round-trip", func(t *testing.T) {
+		bb, err := json.Marshal(r)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"x":100,"y":50,"width":200,"height":100}`, string(bb))
+
+		var got bspc.Rectangle
+		require.NoError(t, json.Unmarshal(bb, &got))
+		assert.Equal(t, r, got)
+	})
+
+	t.Run("should hit-test points, excluding the far edges", func(t *testing.T) {
+		assert.True(t, r.Contains(bspc.Point{X: 100, Y: 50}))
+		assert.True(t, r.Contains(r.Center()))
+		assert.False(t, r.Contains(bspc.Point{X: 300, Y: 100}))
+		assert.False(t, r.Contains(bspc.Point{X: 99, Y: 60}))
+	})
+
+	t.Run("should intersect and unite rectangles", func(t *testing.T) {
+		o := bspc.Rectangle{X: 250, Y: 0, Width: 100, Height: 100}
+
+		assert.Equal(t, bspc.Rectangle{X: 250, Y: 50, Width: 50, Height: 50}, r.Intersect(o))
+		assert.Equal(t, bspc.Rectangle{}, r.Intersect(bspc.Rectangle{X: 300, Y: 50, Width: 10, Height: 10}))
+		assert.Equal(t, bspc.Rectangle{X: 100, Y: 0, Width: 250, Height: 150}, r.Union(o))
+		assert.Equal(t, r, r.Union(bspc.Rectangle{}))
+		assert.Equal(t, 20000, r.Area())
+	})
+
+	t.Run("should inset and split like bspwm", func(t *testing.T) {
+		assert.Equal(t, bspc.Rectangle{X: 110, Y: 70, Width: 170, Height: 50}, r.Inset(bspc.Padding{Top: 20, Right: 20, Bottom: 30, Left: 10}))
+		assert.Equal(t, 0, r.Inset(bspc.Padding{Left: 150, Right: 150}).Width)
+
+		first, second := r.Split(bspc.SplitTypeVertical, 0.33)
+		assert.Equal(t, bspc.Rectangle{X: 100, Y: 50, Width: 66, Height: 100}, first)
+		assert.Equal(t, bspc.Rectangle{X: 166, Y: 50, Width: 134, Height: 100}, second)
+
+		first, second = r.Split(bspc.SplitTypeHorizontal, 0.5)
+		assert.Equal(t, bspc.Rectangle{X: 100, Y: 50, Width: 200, Height: 50}, first)
+		assert.Equal(t, bspc.Rectangle{X: 100, Y: 100, Width: 200, Height: 50}, second)
+	})
+}
@@ -1,31 +1,43 @@
 package bspc
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"net"
 	"strings"
+	"time"
 )
 
 var errInvalidUnixSocket = errors.New("invalid unix socket")
 
-type ipcCommand string
+// ipcCommand holds the words of a command, as bspwm receives them.
+type ipcCommand []string
+
+// newIPCCommand splits a "raw" command into its words.
+func newIPCCommand(rawCmd string) ipcCommand {
+	return strings.Split(rawCmd, " ")
+}
 
 // intoMessage adds NULL to the end of every word in the command.
 // This is necessary because bspwm's C code expects it.
 func (ic ipcCommand) intoMessage() string {
 	var msg string
 
-	words := strings.Split(string(ic), " ")
-	for _, w := range words {
+	for _, w := range ic {
 		msg += w + "\x00"
 	}
 
 	return msg
 }
 
+func (ic ipcCommand) String() string {
+	return strings.Join(ic, " ")
+}
+
 // TODO: Try using monkey-patching to facilitate unit testing for this: var resolveAddr = func() {//...} and then replacing that in the test file and here.
 func newUnixSocketAddress(path string) (*net.UnixAddr, error) {
 	addr, err := net.ResolveUnixAddr("unixgram", path)
@@ -41,84 +53,149 @@ type ipcConn struct {
 	socketConn *net.UnixConn
 }
 
-func newIPCConn(unixSocketAddr *net.UnixAddr) (ipcConn, error) {
+func newIPCConn(ctx context.Context, unixSocketAddr *net.UnixAddr) (ipcConn, error) {
+	var dialer net.Dialer
+
 	// TODO: For this line too
-	conn, err := net.DialUnix("unix", nil, unixSocketAddr)
+	conn, err := dialer.DialContext(ctx, "unix", unixSocketAddr.String())
 	if err != nil {
+		if ctxErr := contextError(ctx, err); ctxErr != nil {
+			return ipcConn{}, ctxErr
+		}
+
 		return ipcConn{}, fmt.Errorf("%w: %v", errInvalidUnixSocket, err)
 	}
 
 	return ipcConn{
 		socketAddr: unixSocketAddr,
-		socketConn: conn,
+		socketConn: conn.(*net.UnixConn),
 	}, nil
 }
 
+// watch applies the context's deadline to the connection, and interrupts any blocked
+// reads or writes as soon as the context is done.
+// The returned function releases the watcher, and must be called once the connection is no longer in use.
+func (ipc ipcConn) watch(ctx context.Context) func() {
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = ipc.socketConn.SetDeadline(deadline)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			// A deadline in the past makes every pending operation on the socket fail immediately.
+			_ = ipc.socketConn.SetDeadline(time.Unix(1, 0))
+		case <-done:
+		}
+	}()
+
+	return func() { close(done) }
+}
+
 func (ipc ipcConn) Send(cmd ipcCommand) error {
 	// TODO: For this line too
 	if _, err := ipc.socketConn.Write([]byte(cmd.intoMessage())); err != nil {
-		return fmt.Errorf("failed to send message: %v", err)
+		return fmt.Errorf("failed to send message: %w", err)
 	}
 
 	return nil
 }
 
-func (ipc ipcConn) Receive() ([]byte, error) {
+// Receive reads from the socket until bspwm closes the connection.
+// If the context ends before that, its error is returned instead.
+func (ipc ipcConn) Receive(ctx context.Context) ([]byte, error) {
 	const maxBufferSize = 512
 
 	var msg []byte
 	for buffer := make([]byte, maxBufferSize); ; buffer = make([]byte, maxBufferSize) {
 		// TODO: For this line too
-		_, _, err := ipc.socketConn.ReadFromUnix(buffer)
+		n, _, err := ipc.socketConn.ReadFromUnix(buffer)
 		if err != nil {
 			if errors.Is(err, io.EOF) {
 				break
 			}
 
+			if ctxErr := contextError(ctx, err); ctxErr != nil {
+				return nil, ctxErr
+			}
+
 			return nil, fmt.Errorf("failed to receive response: %v", err)
 		}
 
-		msg = append(msg, buffer...)
+		msg = append(msg, buffer[:n]...)
 	}
 
 	return bytes.Trim(msg, "\x00"), nil
 }
 
-func (ipc ipcConn) ReceiveAsync() (chan []byte, chan error) {
+// ReceiveAsync reads newline-terminated messages from the socket in the background, until bspwm closes
+// the connection, a read fails or the context ends. Messages are sent whole, however they were split across reads.
+// Both channels are closed, and the connection with them, once that happens.
+// Cancelling the context is not reported as an error.
+func (ipc ipcConn) ReceiveAsync(ctx context.Context) (chan []byte, chan error) {
 	var (
 		resCh = make(chan []byte)
 		errCh = make(chan error, 1)
 	)
 
-	const maxBufferSize = 512
+	const (
+		initialBufferSize = 512
+		maxMessageSize    = 1024 * 1024
+	)
+
+	stopWatching := ipc.watch(ctx)
 
 	go func(resCh chan []byte, errCh chan error) {
-		for buffer := make([]byte, maxBufferSize); ; buffer = make([]byte, maxBufferSize) {
-			_, _, err := ipc.socketConn.ReadFromUnix(buffer)
-			if err != nil {
-				if errors.Is(err, io.EOF) {
-					break
-				}
-
-				errCh <- fmt.Errorf("failed to receive response: %v", err)
-				break
+		defer func() {
+			stopWatching()
+			_ = ipc.Close()
+			close(resCh)
+			close(errCh)
+		}()
+
+		scanner := bufio.NewScanner(ipc.socketConn)
+		scanner.Buffer(make([]byte, 0, initialBufferSize), maxMessageSize)
+
+		for scanner.Scan() {
+			line := bytes.Trim(scanner.Bytes(), "\x00")
+			if len(line) == 0 {
+				continue
 			}
 
-			if len(buffer) == 0 {
-				errCh <- errors.New("response was empty")
-				break
-			}
+			// The scanner reuses its buffer, so the message needs its own copy.
+			msg := append([]byte(nil), line...)
 
-			buffer = bytes.Trim(buffer, "\x00")
-			for _, res := range bytes.Split(buffer, []byte("\n")) { // This is needed because events sent in quick succession will be "glued" together, sometimes.
-				resCh <- res
+			select {
+			case resCh <- msg:
+			case <-ctx.Done():
+				return
 			}
 		}
+
+		if err := scanner.Err(); err != nil && contextError(ctx, err) == nil {
+			errCh <- fmt.Errorf("failed to receive response: %v", err)
+		}
 	}(resCh, errCh)
 
 	return resCh, errCh
 }
 
+// contextError returns the context's error, if the failed socket operation was interrupted because of it.
+// The socket's deadline can expire slightly before the context's, in which case the context has no error yet.
+func contextError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+
+	var netErr net.Error
+	if deadline, ok := ctx.Deadline(); ok && errors.As(err, &netErr) && netErr.Timeout() && !time.Now().Before(deadline) {
+		return context.DeadlineExceeded
+	}
+
+	return nil
+}
+
 func (ipc ipcConn) Close() error {
 	return ipc.socketConn.Close()
 }
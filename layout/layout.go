@@ -0,0 +1,241 @@
+// Package layout computes the rectangles bspwm gives to the nodes of a desktop, without a running bspwm.
+//
+// It mirrors bspwm's own arrange and apply_layout functions, so that layout changes, such as a new
+// split ratio or a preselection, can be previewed or unit-tested before being applied.
+// Example usage:
+//
+//	arranged := layout.Arrange(monitor, desktop, layout.Settings{GaplessMonocle: true})
+//	fmt.Println(arranged.Root.FirstChild.Client.TiledRectangle)
+package layout
+
+import (
+	"github.com/diogox/bspc-go"
+)
+
+// Settings holds the global bspwm settings that affect layouts. Their zero values are bspwm's defaults.
+type Settings struct {
+	// MonoclePadding is added to the desktop's padding, in monocle mode.
+	MonoclePadding bspc.Padding
+	// GaplessMonocle removes the window gap, in monocle mode.
+	GaplessMonocle bool
+	// BorderlessMonocle removes the borders of tiled windows, in monocle mode.
+	BorderlessMonocle bool
+	// SingleMonocle switches to monocle mode while there's at most one tiled window.
+	SingleMonocle bool
+	// BorderlessSingleton removes the border of the desktop's window, while it's the only one.
+	// The monitor is assumed to be the only one, which bspwm also requires.
+	BorderlessSingleton bool
+	// CenterPseudoTiled centers pseudo-tiled windows in their tiling rectangles.
+	CenterPseudoTiled bool
+}
+
+// Arrange returns a copy of the desktop, with the rectangles bspwm would give to its nodes on the monitor.
+// The monitor's rectangle and padding are used, along with the desktop's layout, padding and window gap.
+// Each node's Rectangle is set to its tiling area, and each client's TiledRectangle to its window's geometry,
+// borders excluded. The split ratios are adjusted to honor the nodes' constraints, as bspwm does.
+func Arrange(m bspc.Monitor, d bspc.Desktop, s Settings) bspc.Desktop {
+	d.Root = *clone(&d.Root)
+
+	if d.Root.ID == bspc.NilID {
+		return d
+	}
+
+	l := d.Layout
+	if s.SingleMonocle && tiledCount(&d.Root) <= 1 {
+		l = bspc.LayoutTypeMonocle
+	}
+
+	rect := m.Rectangle.Inset(m.Padding.Add(d.Padding))
+	if l == bspc.LayoutTypeMonocle {
+		rect = rect.Inset(s.MonoclePadding)
+	}
+
+	gap := d.WindowGap
+	if s.GaplessMonocle && l == bspc.LayoutTypeMonocle {
+		gap = 0
+	}
+
+	// Every node's area includes a window gap on its right and bottom edges, so only the top left one is added here.
+	rect.X += gap
+	rect.Y += gap
+	rect.Width -= gap
+	rect.Height -= gap
+
+	a := arranger{monitor: m, desktop: &d, settings: s, layout: l, gap: gap}
+	a.apply(&d.Root, rect)
+
+	return d
+}
+
+type arranger struct {
+	monitor  bspc.Monitor
+	desktop  *bspc.Desktop
+	settings Settings
+	layout   bspc.LayoutType
+	gap      int
+}
+
+func (a arranger) apply(n *bspc.Node, rect bspc.Rectangle) {
+	if n == nil {
+		return
+	}
+
+	n.Rectangle = rect
+
+	if n.FirstChild == nil || n.SecondChild == nil {
+		a.applyLeaf(n, rect)
+		return
+	}
+
+	first, second := rect, rect
+	if a.layout != bspc.LayoutTypeMonocle && !isVacant(n.FirstChild) && !isVacant(n.SecondChild) {
+		first, second = split(n, rect)
+	}
+
+	a.apply(n.FirstChild, first)
+	a.apply(n.SecondChild, second)
+}
+
+func (a arranger) applyLeaf(n *bspc.Node, rect bspc.Rectangle) {
+	c := n.Client
+	if c == nil {
+		return
+	}
+
+	bw := c.BorderWidth
+	theOnlyWindow := a.desktop.Root.Client != nil
+	if (a.settings.BorderlessMonocle && a.layout == bspc.LayoutTypeMonocle && isTiled(c)) ||
+		(a.settings.BorderlessSingleton && theOnlyWindow) ||
+		c.State == bspc.StateTypeFullscreen {
+		bw = 0
+	}
+
+	switch c.State {
+	case bspc.StateTypeTiled, bspc.StateTypePseudoTiled:
+		r := rect
+
+		bleed := a.gap + 2*bw
+		r.Width = shrink(r.Width, bleed)
+		r.Height = shrink(r.Height, bleed)
+
+		if c.State == bspc.StateTypePseudoTiled {
+			f := c.FloatingRectangle
+			if f.Width < r.Width {
+				r.Width = f.Width
+			}
+
+			if f.Height < r.Height {
+				r.Height = f.Height
+			}
+
+			if a.settings.CenterPseudoTiled {
+				r.X = rect.X - bw + (rect.Width-a.gap-r.Width)/2
+				r.Y = rect.Y - bw + (rect.Height-a.gap-r.Height)/2
+			}
+		}
+
+		c.TiledRectangle = r
+	case bspc.StateTypeFullscreen:
+		c.TiledRectangle = a.monitor.Rectangle
+	}
+}
+
+// split divides the node's rectangle between its children, moving the fence to honor their constraints
+// when they fit, and updating the node's split ratio accordingly.
+func split(n *bspc.Node, rect bspc.Rectangle) (first, second bspc.Rectangle) {
+	size, firstMin, secondMin := rect.Height, n.FirstChild.Constraints.MinHeight, n.SecondChild.Constraints.MinHeight
+	if n.SplitType == bspc.SplitTypeVertical {
+		size, firstMin, secondMin = rect.Width, n.FirstChild.Constraints.MinWidth, n.SecondChild.Constraints.MinWidth
+	}
+
+	fence := int(float64(size) * n.SplitRatio)
+	if firstMin+secondMin <= size {
+		switch {
+		case fence < firstMin:
+			fence = firstMin
+			n.SplitRatio = float64(fence) / float64(size)
+		case fence > size-secondMin:
+			fence = size - secondMin
+			n.SplitRatio = float64(fence) / float64(size)
+		}
+	}
+
+	first, second = rect, rect
+	if n.SplitType == bspc.SplitTypeVertical {
+		first.Width = fence
+		second.X += fence
+		second.Width -= fence
+	} else {
+		first.Height = fence
+		second.Y += fence
+		second.Height -= fence
+	}
+
+	return first, second
+}
+
+// isVacant returns true if none of the node's leaves take part in tiling.
+func isVacant(n *bspc.Node) bool {
+	if n.Hidden {
+		return true
+	}
+
+	if n.FirstChild == nil || n.SecondChild == nil {
+		// Receptacles take part in tiling.
+		return n.Client != nil && !isTiled(n.Client)
+	}
+
+	return isVacant(n.FirstChild) && isVacant(n.SecondChild)
+}
+
+// tiledCount returns the number of visible leaves taking part in tiling, receptacles included.
+func tiledCount(root *bspc.Node) int {
+	count := 0
+	root.Walk(bspc.WalkOrderTypePre, func(n *bspc.Node) bool {
+		isLeaf := n.FirstChild == nil && n.SecondChild == nil
+		if isLeaf && !n.Hidden && (n.Client == nil || isTiled(n.Client)) {
+			count++
+		}
+
+		return true
+	})
+
+	return count
+}
+
+func isTiled(c *bspc.NodeClient) bool {
+	return c.State == bspc.StateTypeTiled || c.State == bspc.StateTypePseudoTiled
+}
+
+// shrink removes the bleed from the dimension, without going below a single pixel, as bspwm does.
+func shrink(dimension, bleed int) int {
+	if bleed < dimension {
+		return dimension - bleed
+	}
+
+	return 1
+}
+
+func clone(n *bspc.Node) *bspc.Node {
+	c := *n
+
+	if n.Client != nil {
+		client := *n.Client
+		c.Client = &client
+	}
+
+	if n.Preselect != nil {
+		presel := *n.Preselect
+		c.Preselect = &presel
+	}
+
+	if n.FirstChild != nil {
+		c.FirstChild = clone(n.FirstChild)
+	}
+
+	if n.SecondChild != nil {
+		c.SecondChild = clone(n.SecondChild)
+	}
+
+	return &c
+}
@@ -0,0 +1,96 @@
+package layout_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/diogox/bspc-go"
+	"github.com/diogox/bspc-go/layout"
+)
+
+func TestArrange(t *testing.T) {
+	// A 1920x1080 monitor, with a 20px bar on top.
+	monitor := bspc.Monitor{
+		Rectangle: bspc.Rectangle{Width: 1920, Height: 1080},
+		Padding:   bspc.Padding{Top: 20},
+	}
+
+	window := func(id bspc.ID, state bspc.StateType) *bspc.Node {
+		return &bspc.Node{ID: id, Client: &bspc.NodeClient{BorderWidth: 2, State: state}}
+	}
+
+	newDesktop := func(layoutType bspc.LayoutType, first, second *bspc.Node) bspc.Desktop {
+		return bspc.Desktop{
+			Layout:    layoutType,
+			WindowGap: 10,
+			Root: bspc.Node{
+				ID:          1,
+				SplitType:   bspc.SplitTypeVertical,
+				SplitRatio:  0.5,
+				FirstChild:  first,
+				SecondChild: second,
+			},
+		}
+	}
+
+	t.Run("should split tiled windows, with gaps and borders", func(t *testing.T) {
+		d := newDesktop(bspc.LayoutTypeTiled, window(2, bspc.StateTypeTiled), window(3, bspc.StateTypeTiled))
+
+		arranged := layout.Arrange(monitor, d, layout.Settings{})
+
+		root := arranged.Root
+		assert.Equal(t, bspc.Rectangle{X: 10, Y: 30, Width: 1910, Height: 1050}, root.Rectangle)
+		assert.Equal(t, bspc.Rectangle{X: 10, Y: 30, Width: 955, Height: 1050}, root.FirstChild.Rectangle)
+		assert.Equal(t, bspc.Rectangle{X: 965, Y: 30, Width: 955, Height: 1050}, root.SecondChild.Rectangle)
+		assert.Equal(t, bspc.Rectangle{X: 10, Y: 30, Width: 941, Height: 1036}, root.FirstChild.Client.TiledRectangle)
+		assert.Equal(t, bspc.Rectangle{X: 965, Y: 30, Width: 941, Height: 1036}, root.SecondChild.Client.TiledRectangle)
+
+		// The original desktop is left untouched.
+		assert.Equal(t, bspc.Rectangle{}, d.Root.FirstChild.Client.TiledRectangle)
+	})
+
+	t.Run("should stack windows in gapless and borderless monocle mode", func(t *testing.T) {
+		d := newDesktop(bspc.LayoutTypeMonocle, window(2, bspc.StateTypeTiled), window(3, bspc.StateTypeTiled))
+
+		arranged := layout.Arrange(monitor, d, layout.Settings{
+			GaplessMonocle:    true,
+			BorderlessMonocle: true,
+			MonoclePadding:    bspc.Padding{Left: 100, Right: 100},
+		})
+
+		want := bspc.Rectangle{X: 100, Y: 20, Width: 1720, Height: 1060}
+		assert.Equal(t, want, arranged.Root.FirstChild.Client.TiledRectangle)
+		assert.Equal(t, want, arranged.Root.SecondChild.Client.TiledRectangle)
+	})
+
+	t.Run("should give the whole area to the sibling of a floating window", func(t *testing.T) {
+		d := newDesktop(bspc.LayoutTypeTiled, window(2, bspc.StateTypeTiled), window(3, bspc.StateTypeFloating))
+		d.Root.SecondChild.Client.FloatingRectangle = bspc.Rectangle{X: 500, Y: 500, Width: 300, Height: 200}
+
+		arranged := layout.Arrange(monitor, d, layout.Settings{})
+
+		assert.Equal(t, bspc.Rectangle{X: 10, Y: 30, Width: 1896, Height: 1036}, arranged.Root.FirstChild.Client.TiledRectangle)
+		assert.Equal(t, bspc.Rectangle{}, arranged.Root.SecondChild.Client.TiledRectangle)
+	})
+
+	t.Run("should move the fence to honor the constraints", func(t *testing.T) {
+		d := newDesktop(bspc.LayoutTypeTiled, window(2, bspc.StateTypeTiled), window(3, bspc.StateTypeTiled))
+		d.Root.SplitRatio = 0.1
+		d.Root.FirstChild.Constraints = bspc.Constraints{MinWidth: 382}
+
+		arranged := layout.Arrange(monitor, d, layout.Settings{})
+
+		assert.Equal(t, 382, arranged.Root.FirstChild.Rectangle.Width)
+		assert.Equal(t, 0.2, arranged.Root.SplitRatio)
+	})
+
+	t.Run("should center pseudo-tiled windows", func(t *testing.T) {
+		d := newDesktop(bspc.LayoutTypeTiled, window(2, bspc.StateTypePseudoTiled), window(3, bspc.StateTypeTiled))
+		d.Root.FirstChild.Client.FloatingRectangle = bspc.Rectangle{Width: 400, Height: 300}
+
+		arranged := layout.Arrange(monitor, d, layout.Settings{CenterPseudoTiled: true})
+
+		assert.Equal(t, bspc.Rectangle{X: 280, Y: 398, Width: 400, Height: 300}, arranged.Root.FirstChild.Client.TiledRectangle)
+	})
+}
@@ -0,0 +1,226 @@
+package layouts
+
+import (
+	"context"
+	"math"
+
+	"github.com/diogox/bspc-go"
+)
+
+// ratioTolerance absorbs the precision lost by bspwm when printing split ratios.
+const ratioTolerance = 0.001
+
+type (
+	// tree is the part of a desktop's tree that takes part in the layout: its tiled windows, and the nodes
+	// splitting them. Nodes that only split a window from nodes left out of the layout are skipped.
+	tree struct {
+		node        *bspc.Node
+		firstChild  *tree
+		secondChild *tree
+	}
+
+	arranger struct {
+		ctx    context.Context
+		client bspc.Client
+	}
+)
+
+// Arrange reshapes the desktop's tree into the one the layout gives to its tiled windows.
+// Floating, fullscreen and hidden windows are left out of the layout, and so are receptacles.
+// The tree is adjusted in place when it already has the layout's shape, give or take the order of
+// some siblings, and rebuilt window by window otherwise. Desktops in monocle mode are left alone.
+func Arrange(ctx context.Context, c bspc.Client, d bspc.Desktop, layout Name, o Options) error {
+	if d.UserLayout == bspc.LayoutTypeMonocle || d.Root.ID == bspc.NilID {
+		return nil
+	}
+
+	t := layoutTree(&d.Root)
+	if t == nil {
+		return nil
+	}
+
+	s := layout.Shape(t.leafCount(), o)
+	if s == nil {
+		return nil
+	}
+
+	a := arranger{ctx: ctx, client: c}
+	if !t.fits(s) {
+		return a.rebuild(t.leaves(), s)
+	}
+
+	return a.adjust(t, s)
+}
+
+// adjust fixes the split types, the order of the children, and the split ratios of a tree that fits the shape.
+func (a arranger) adjust(t *tree, s *Shape) error {
+	if s.IsLeaf() {
+		return nil
+	}
+
+	n := t.node
+	if n.SplitType != s.SplitType {
+		if err := a.setSplitType(n, s.SplitType); err != nil {
+			return err
+		}
+	}
+
+	if !t.firstChild.fits(s.FirstChild) || !t.secondChild.fits(s.SecondChild) {
+		if err := a.node(n.FirstChild.ID).Swap(bspc.ByID(n.SecondChild.ID)); err != nil {
+			return err
+		}
+
+		n.FirstChild, n.SecondChild = n.SecondChild, n.FirstChild
+		t.firstChild, t.secondChild = t.secondChild, t.firstChild
+	}
+
+	if math.Abs(n.SplitRatio-s.SplitRatio) > ratioTolerance {
+		if err := a.node(n.ID).Ratio(s.SplitRatio); err != nil {
+			return err
+		}
+	}
+
+	if err := a.adjust(t.firstChild, s.FirstChild); err != nil {
+		return err
+	}
+
+	return a.adjust(t.secondChild, s.SecondChild)
+}
+
+// setSplitType changes the split type of the node alone. Rotating a node rotates all of its descendants too,
+// so each of its children is rotated back afterwards. The angles are chosen so that bspwm doesn't swap the
+// node's children, as it does when rotating horizontal splits by 90 degrees, and vertical ones by 270.
+func (a arranger) setSplitType(n *bspc.Node, split bspc.SplitType) error {
+	angle, back := bspc.RotationType90, bspc.RotationType270
+	if n.SplitType == bspc.SplitTypeHorizontal {
+		angle, back = bspc.RotationType270, bspc.RotationType90
+	}
+
+	if err := a.node(n.ID).Rotate(angle); err != nil {
+		return err
+	}
+
+	for _, child := range []*bspc.Node{n.FirstChild, n.SecondChild} {
+		if isLeafNode(child) {
+			continue
+		}
+
+		if err := a.node(child.ID).Rotate(back); err != nil {
+			return err
+		}
+	}
+
+	n.SplitType = split
+
+	return nil
+}
+
+// rebuild moves the windows into the shape, in order. The first window stays where it is, and each split of
+// the shape is made by preselecting its first window, and moving the first window of its second child there.
+// The windows placed so far always form a subtree, so the ones left behind don't get in the way.
+func (a arranger) rebuild(windows []bspc.ID, s *Shape) error {
+	if s.IsLeaf() {
+		return nil
+	}
+
+	first, second := windows[:s.FirstChild.LeafCount()], windows[s.FirstChild.LeafCount():]
+
+	dir := bspc.DirectionTypeDown
+	if s.SplitType == bspc.SplitTypeVertical {
+		dir = bspc.DirectionTypeRight
+	}
+
+	anchor := a.node(first[0])
+	if err := anchor.Presel(dir); err != nil {
+		return err
+	}
+
+	if err := anchor.PreselRatio(s.SplitRatio); err != nil {
+		return err
+	}
+
+	if err := a.node(second[0]).ToNode(bspc.ByID(first[0]), false); err != nil {
+		return err
+	}
+
+	if err := a.rebuild(first, s.FirstChild); err != nil {
+		return err
+	}
+
+	return a.rebuild(second, s.SecondChild)
+}
+
+func (a arranger) node(id bspc.ID) bspc.NodeCommand {
+	return a.client.Node(bspc.ByID(id)).WithContext(a.ctx)
+}
+
+// layoutTree returns the part of the tree rooted at the node that takes part in the layout, or nil if there's none.
+func layoutTree(n *bspc.Node) *tree {
+	if isLeafNode(n) {
+		if !inLayout(n) {
+			return nil
+		}
+
+		return &tree{node: n}
+	}
+
+	first, second := layoutTree(n.FirstChild), layoutTree(n.SecondChild)
+	switch {
+	case first == nil:
+		return second
+	case second == nil:
+		return first
+	}
+
+	return &tree{node: n, firstChild: first, secondChild: second}
+}
+
+// isLeafNode returns true for windows and receptacles.
+func isLeafNode(n *bspc.Node) bool {
+	return n.FirstChild == nil || n.SecondChild == nil
+}
+
+// inLayout returns true for visible windows that bspwm tiles.
+func inLayout(n *bspc.Node) bool {
+	if n.Client == nil || n.Hidden {
+		return false
+	}
+
+	return n.Client.State == bspc.StateTypeTiled || n.Client.State == bspc.StateTypePseudoTiled
+}
+
+func (t *tree) isLeaf() bool {
+	return t.firstChild == nil
+}
+
+func (t *tree) leafCount() int {
+	if t.isLeaf() {
+		return 1
+	}
+
+	return t.firstChild.leafCount() + t.secondChild.leafCount()
+}
+
+// leaves returns the IDs of the windows in the tree, from left to right.
+func (t *tree) leaves() []bspc.ID {
+	if t.isLeaf() {
+		return []bspc.ID{t.node.ID}
+	}
+
+	return append(t.firstChild.leaves(), t.secondChild.leaves()...)
+}
+
+// fits returns true if the tree has the shape, once some of its siblings are swapped.
+// Split types and ratios aren't compared, as they can be changed in place.
+func (t *tree) fits(s *Shape) bool {
+	if s.IsLeaf() || t.isLeaf() {
+		return s.IsLeaf() && t.isLeaf()
+	}
+
+	if t.leafCount() != s.LeafCount() {
+		return false
+	}
+
+	return (t.firstChild.fits(s.FirstChild) && t.secondChild.fits(s.SecondChild)) ||
+		(t.firstChild.fits(s.SecondChild) && t.secondChild.fits(s.FirstChild))
+}
@@ -0,0 +1,237 @@
+package layouts_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diogox/bspc-go"
+	"github.com/diogox/bspc-go/bspctest"
+	"github.com/diogox/bspc-go/layouts"
+)
+
+const (
+	desktopID = bspc.ID(0x00200003)
+	rootID    = bspc.ID(0x00400001)
+	stackID   = bspc.ID(0x00400002)
+	aID       = bspc.ID(0x00E00003)
+	bID       = bspc.ID(0x00E00004)
+	cID       = bspc.ID(0x00E00005)
+	dID       = bspc.ID(0x00E00006)
+)
+
+func window(id bspc.ID, state bspc.StateType) *bspc.Node {
+	return &bspc.Node{ID: id, Client: &bspc.NodeClient{State: state, Layer: bspc.LayerTypeNormal}}
+}
+
+func internal(id bspc.ID, t bspc.SplitType, ratio float64, first, second *bspc.Node) *bspc.Node {
+	return &bspc.Node{ID: id, SplitType: t, SplitRatio: ratio, FirstChild: first, SecondChild: second}
+}
+
+func desktop(root *bspc.Node) bspc.Desktop {
+	return bspc.Desktop{ID: desktopID, Name: "I", Layout: bspc.LayoutTypeTiled, UserLayout: bspc.LayoutTypeTiled, Root: *root}
+}
+
+func TestArrange(t *testing.T) {
+	const (
+		v = bspc.SplitTypeVertical
+		h = bspc.SplitTypeHorizontal
+	)
+
+	tests := []struct {
+		name    string
+		desktop bspc.Desktop
+		layout  layouts.Name
+		options layouts.Options
+		want    []string
+	}{
+		{
+			name:    "should leave trees already in the layout's shape alone",
+			desktop: desktop(internal(rootID, v, 0.5, window(aID, bspc.StateTypeTiled), internal(stackID, h, 0.5, window(bID, bspc.StateTypeTiled), window(cID, bspc.StateTypeTiled)))),
+			layout:  layouts.Tall,
+			want:    nil,
+		},
+		{
+			name:    "should rotate the nodes with the wrong split type, and rotate their children back",
+			desktop: desktop(internal(rootID, h, 0.5, window(aID, bspc.StateTypeTiled), internal(stackID, v, 0.5, window(bID, bspc.StateTypeTiled), window(cID, bspc.StateTypeTiled)))),
+			layout:  layouts.Tall,
+			want: []string{
+				"node 0x00400001 --rotate 270",
+				"node 0x00400002 --rotate 90",
+				"node 0x00400002 --rotate 90",
+			},
+		},
+		{
+			name:    "should swap the children in the wrong order, and fix the ratios",
+			desktop: desktop(internal(rootID, v, 0.6, window(aID, bspc.StateTypeTiled), internal(stackID, h, 0.5, window(bID, bspc.StateTypeTiled), window(cID, bspc.StateTypeTiled)))),
+			layout:  layouts.RTall,
+			options: layouts.Options{MasterRatio: 0.6},
+			want: []string{
+				"node 0x00E00003 --swap 0x00400002",
+				"node 0x00400001 --ratio 0.4",
+			},
+		},
+		{
+			name: "should rebuild trees of another shape, keeping the order of the windows",
+			desktop: desktop(internal(rootID, v, 0.5,
+				internal(stackID, h, 0.5, window(aID, bspc.StateTypeTiled), window(bID, bspc.StateTypeTiled)),
+				internal(0x00400003, h, 0.5, window(cID, bspc.StateTypeTiled), window(dID, bspc.StateTypeTiled)),
+			)),
+			layout: layouts.Wide,
+			want: []string{
+				"node 0x00E00003 --presel-dir south",
+				"node 0x00E00003 --presel-ratio 0.5",
+				"node 0x00E00004 --to-node 0x00E00003",
+				"node 0x00E00004 --presel-dir east",
+				"node 0x00E00004 --presel-ratio 0.3333333333333333",
+				"node 0x00E00005 --to-node 0x00E00004",
+				"node 0x00E00005 --presel-dir east",
+				"node 0x00E00005 --presel-ratio 0.5",
+				"node 0x00E00006 --to-node 0x00E00005",
+			},
+		},
+		{
+			name:    "should leave floating windows out of the layout",
+			desktop: desktop(internal(rootID, h, 0.5, window(aID, bspc.StateTypeTiled), window(bID, bspc.StateTypeFloating))),
+			layout:  layouts.Tall,
+			want:    nil,
+		},
+		{
+			name: "should leave desktops in monocle mode alone",
+			desktop: bspc.Desktop{
+				ID:         desktopID,
+				Layout:     bspc.LayoutTypeMonocle,
+				UserLayout: bspc.LayoutTypeMonocle,
+				Root:       *internal(rootID, h, 0.5, window(aID, bspc.StateTypeTiled), window(bID, bspc.StateTypeTiled)),
+			},
+			layout: layouts.Tall,
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, srv := bspctest.NewClient(t)
+
+			require.NoError(t, layouts.Arrange(context.Background(), c, tt.desktop, tt.layout, tt.options))
+			assert.Equal(t, tt.want, srv.Commands())
+		})
+	}
+}
+
+func TestEngine_Run(t *testing.T) {
+	t.Run("should arrange the desktops with a layout, after their windows change", func(t *testing.T) {
+		c, srv := bspctest.NewClient(t)
+		srv.RespondJSON("query --tree --desktop 0x00200003", desktop(internal(rootID, bspc.SplitTypeHorizontal, 0.5,
+			window(aID, bspc.StateTypeTiled),
+			window(bID, bspc.StateTypeTiled),
+		)))
+
+		e := layouts.NewEngine(c, nil, layouts.Options{})
+		e.SetLayout(desktopID, layouts.Tall)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- e.Run(ctx)
+		}()
+
+		srv.WaitForSubscribers(1)
+		srv.Publish(
+			"node_add 0x00200002 0x00200004 0x00E00007 0x00E00008",
+			"node_add 0x00200002 0x00200003 0x00E00003 0x00E00004",
+		)
+
+		assert.Eventually(t, func() bool {
+			for _, cmd := range srv.Commands() {
+				if cmd == "node 0x00400001 --rotate 270" {
+					return true
+				}
+			}
+
+			return false
+		}, time.Second, 10*time.Millisecond)
+
+		assert.NotContains(t, srv.Commands(), "query --tree --desktop 0x00200004")
+
+		cancel()
+		assert.ErrorIs(t, <-errCh, context.Canceled)
+	})
+}
+
+func TestEngine_Arrange(t *testing.T) {
+	t.Run("should arrange one desktop at a time", func(t *testing.T) {
+		const query = "query --tree --desktop 0x00200003"
+
+		bb, err := json.Marshal(desktop(window(aID, bspc.StateTypeTiled)))
+		require.NoError(t, err)
+
+		c, srv := bspctest.NewClient(t)
+
+		release := make(chan struct{})
+		srv.RespondFunc(query, func() string {
+			<-release
+			return string(bb)
+		})
+
+		e := layouts.NewEngine(c, nil, layouts.Options{})
+		e.SetLayout(desktopID, layouts.Tall)
+
+		errCh := make(chan error, 2)
+		for i := 0; i < 2; i++ {
+			go func() { errCh <- e.Arrange(context.Background(), desktopID) }()
+		}
+
+		queries := func() int {
+			var n int
+			for _, cmd := range srv.Commands() {
+				if cmd == query {
+					n++
+				}
+			}
+
+			return n
+		}
+
+		require.Eventually(t, func() bool { return queries() == 1 }, time.Second, time.Millisecond)
+		assert.Never(t, func() bool { return queries() > 1 }, 100*time.Millisecond, 10*time.Millisecond)
+
+		close(release)
+		require.NoError(t, <-errCh)
+		require.NoError(t, <-errCh)
+		assert.Equal(t, 2, queries())
+	})
+}
+
+func TestEngine_Layout(t *testing.T) {
+	c, _ := bspctest.NewClient(t)
+
+	t.Run("should fall back on the default layout", func(t *testing.T) {
+		e := layouts.NewEngine(c, nil, layouts.Options{})
+		e.SetDefaultLayout(layouts.Grid)
+		e.SetLayout(desktopID, layouts.Wide)
+
+		layout, ok := e.Layout(desktopID)
+		assert.True(t, ok)
+		assert.Equal(t, layouts.Wide, layout)
+
+		layout, ok = e.Layout(0x00200004)
+		assert.True(t, ok)
+		assert.Equal(t, layouts.Grid, layout)
+	})
+
+	t.Run("should have no layout for desktops that were given none", func(t *testing.T) {
+		e := layouts.NewEngine(c, nil, layouts.Options{})
+		e.SetDefaultLayout(layouts.Grid)
+		e.SetLayout(desktopID, "")
+
+		_, ok := e.Layout(desktopID)
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,143 @@
+package layouts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/diogox/bspc-go"
+)
+
+// arrangedEvents are the events after which an Engine arranges the desktops they concern.
+var arrangedEvents = []bspc.EventType{
+	bspc.EventTypeNodeAdd,
+	bspc.EventTypeNodeRemove,
+	bspc.EventTypeNodeTransfer,
+	bspc.EventTypeDesktopLayout,
+}
+
+// Engine keeps the desktops in the shape of their layouts, as windows are added, removed or moved around.
+// The layout of each desktop is only kept in memory, so it's lost when the engine stops.
+// Desktops are arranged one at a time, so that the commands of concurrent arrangements don't interleave.
+type Engine struct {
+	client  bspc.Client
+	logger  bspc.Logger
+	options Options
+
+	// arranging is held while a desktop is arranged.
+	arranging sync.Mutex
+
+	mu            sync.Mutex
+	layouts       map[bspc.ID]Name
+	defaultLayout Name
+}
+
+// NewEngine returns an engine for the bspwm instance behind the client. Desktops have no layout until one is set.
+// If the value passed in as a logger is nil, logging will be disabled.
+func NewEngine(c bspc.Client, logger bspc.Logger, o Options) *Engine {
+	return &Engine{
+		client:  c,
+		logger:  logger,
+		options: o,
+		layouts: make(map[bspc.ID]Name),
+	}
+}
+
+// SetDefaultLayout sets the layout of the desktops that weren't given one. An empty name leaves them alone.
+func (e *Engine) SetDefaultLayout(layout Name) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.defaultLayout = layout
+}
+
+// SetLayout sets the layout of the desktop. An empty name leaves it alone, instead of using the default one.
+// It doesn't arrange the desktop, call Arrange for that.
+func (e *Engine) SetLayout(desktopID bspc.ID, layout Name) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.layouts[desktopID] = layout
+}
+
+// Layout returns the layout of the desktop, and whether it has one.
+func (e *Engine) Layout(desktopID bspc.ID) (Name, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	layout, ok := e.layouts[desktopID]
+	if !ok {
+		layout = e.defaultLayout
+	}
+
+	return layout, layout != ""
+}
+
+// Arrange reshapes the desktop into its layout. It does nothing if the desktop has none.
+// It waits for the arrangements already in progress, such as the ones Run starts, to finish first.
+func (e *Engine) Arrange(ctx context.Context, desktopID bspc.ID) error {
+	e.arranging.Lock()
+	defer e.arranging.Unlock()
+
+	layout, ok := e.Layout(desktopID)
+	if !ok {
+		return nil
+	}
+
+	d, err := e.client.DesktopTreeContext(ctx, bspc.ByID(desktopID))
+	if err != nil {
+		return fmt.Errorf("failed to query desktop: %w", err)
+	}
+
+	return Arrange(ctx, e.client, d, layout, e.options)
+}
+
+// Run subscribes to bspwm's events, and arranges the desktops they concern, until the context is done
+// or the subscription fails. It blocks, and always returns a non-nil error.
+// Failing to arrange a desktop is only logged, as windows can go away while they're being moved.
+func (e *Engine) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	eventCh, errCh, err := e.client.SubscribeEventsContext(ctx, arrangedEvents[0], arrangedEvents[1:]...)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to events: %w", err)
+	}
+
+	return bspc.ConsumeEvents(ctx, eventCh, errCh, e.logger, func(ev bspc.Event) error {
+		// A failure doesn't keep the other desktops from being arranged.
+		var failures []string
+		for _, desktopID := range affectedDesktops(ev) {
+			if err := e.Arrange(ctx, desktopID); err != nil {
+				failures = append(failures, fmt.Sprintf("0x%08X: %v", uint(desktopID), err))
+			}
+		}
+
+		if len(failures) > 0 {
+			return fmt.Errorf("failed to arrange desktops %s", strings.Join(failures, ", "))
+		}
+
+		return nil
+	})
+}
+
+// affectedDesktops returns the IDs of the desktops whose windows changed with the event.
+func affectedDesktops(ev bspc.Event) []bspc.ID {
+	switch p := ev.Payload.(type) {
+	case bspc.EventNodeAdd:
+		return []bspc.ID{p.DesktopID}
+	case bspc.EventNodeRemove:
+		return []bspc.ID{p.DesktopID}
+	case bspc.EventNodeTransfer:
+		if p.SourceDesktopID == p.DestinationDesktopID {
+			return []bspc.ID{p.SourceDesktopID}
+		}
+
+		return []bspc.ID{p.SourceDesktopID, p.DestinationDesktopID}
+	case bspc.EventDesktopLayout:
+		return []bspc.ID{p.DesktopID}
+	}
+
+	return nil
+}
@@ -0,0 +1,201 @@
+// Package layouts adds layouts bspwm doesn't have, such as tall or grid, by keeping each desktop's tree in the
+// shape of the chosen layout, as windows come and go.
+//
+// bspwm only knows of the tiled and monocle layouts. The ones here reshape the tiled layout's tree with
+// rotate, swap and ratio commands when they can, and by moving windows into preselections when the tree
+// needs a different shape. Windows keep the order they have in the tree, from left to right.
+// Example usage:
+//
+//	e := layouts.NewEngine(c, nil, layouts.Options{MasterCount: 2})
+//	e.SetLayout(desktopID, layouts.MasterStack)
+//	err := e.Run(ctx)
+package layouts
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/diogox/bspc-go"
+)
+
+// Name is a layout's name.
+type Name string
+
+const (
+	// Tall puts the first window on the left, and stacks the others on the right.
+	Tall Name = "tall"
+	// RTall puts the last window on the right, and stacks the others on the left.
+	RTall Name = "rtall"
+	// Wide puts the first window at the top, and lines up the others at the bottom.
+	Wide Name = "wide"
+	// Grid arranges the windows in rows and columns of about the same count.
+	Grid Name = "grid"
+	// Even puts the windows side by side, in columns of the same width.
+	Even Name = "even"
+	// MasterStack works like tall, with as many windows on the left as the master count.
+	MasterStack Name = "master-stack"
+)
+
+// Names returns every layout's name.
+func Names() []Name {
+	return []Name{Tall, RTall, Wide, Grid, Even, MasterStack}
+}
+
+// IsValid returns true if the name is one of the layouts in Names.
+func (n Name) IsValid() bool {
+	switch n {
+	case Tall, RTall, Wide, Grid, Even, MasterStack:
+		return true
+	}
+
+	return false
+}
+
+// ParseName returns the layout with the given name.
+func ParseName(s string) (Name, error) {
+	if n := Name(s); n.IsValid() {
+		return n, nil
+	}
+
+	return "", fmt.Errorf("%w: invalid layout %s", bspc.ErrInvalidArgument, s)
+}
+
+// Options tunes the layouts. Their zero values are replaced by the defaults.
+type Options struct {
+	// MasterCount is the number of windows in the master area of the master-stack layout. It defaults to 1.
+	MasterCount int
+	// MasterRatio is the share of the desktop taken by the master area, of the layouts that have one.
+	// It defaults to 0.5.
+	MasterRatio float64
+}
+
+func (o Options) withDefaults() Options {
+	if o.MasterCount <= 0 {
+		o.MasterCount = 1
+	}
+
+	if o.MasterRatio <= 0 || o.MasterRatio >= 1 {
+		o.MasterRatio = 0.5
+	}
+
+	return o
+}
+
+// Shape is the tree a layout gives to a number of windows: either a window, or the split of two other shapes.
+type Shape struct {
+	SplitType   bspc.SplitType
+	SplitRatio  float64
+	FirstChild  *Shape
+	SecondChild *Shape
+}
+
+// IsLeaf returns true if the shape is a window.
+func (s Shape) IsLeaf() bool {
+	return s.FirstChild == nil || s.SecondChild == nil
+}
+
+// LeafCount returns the number of windows in the shape.
+func (s Shape) LeafCount() int {
+	if s.IsLeaf() {
+		return 1
+	}
+
+	return s.FirstChild.LeafCount() + s.SecondChild.LeafCount()
+}
+
+// Shape returns the tree the layout gives to the number of windows, or nil if there are none.
+func (n Name) Shape(windows int, o Options) *Shape {
+	if windows <= 0 {
+		return nil
+	}
+
+	o = o.withDefaults()
+
+	switch n {
+	case Tall:
+		return masterShape(windows, 1, o.MasterRatio, bspc.SplitTypeVertical, false)
+	case RTall:
+		return masterShape(windows, 1, o.MasterRatio, bspc.SplitTypeVertical, true)
+	case Wide:
+		return masterShape(windows, 1, o.MasterRatio, bspc.SplitTypeHorizontal, false)
+	case MasterStack:
+		return masterShape(windows, o.MasterCount, o.MasterRatio, bspc.SplitTypeVertical, false)
+	case Grid:
+		return gridShape(windows)
+	case Even:
+		return evenShape(windows, bspc.SplitTypeVertical)
+	}
+
+	return nil
+}
+
+// masterShape splits the windows between a master area and a stack, along the split type.
+// The windows of each area are split the other way. If reversed, the master area comes last.
+func masterShape(windows, masters int, ratio float64, split bspc.SplitType, reversed bool) *Shape {
+	across := bspc.SplitTypeHorizontal
+	if split == bspc.SplitTypeHorizontal {
+		across = bspc.SplitTypeVertical
+	}
+
+	if windows <= masters {
+		return evenShape(windows, across)
+	}
+
+	if reversed {
+		return &Shape{
+			SplitType:   split,
+			SplitRatio:  1 - ratio,
+			FirstChild:  evenShape(windows-masters, across),
+			SecondChild: evenShape(masters, across),
+		}
+	}
+
+	return &Shape{
+		SplitType:   split,
+		SplitRatio:  ratio,
+		FirstChild:  evenShape(masters, across),
+		SecondChild: evenShape(windows-masters, across),
+	}
+}
+
+// gridShape lines up the windows in rows, with the extra windows in the last rows.
+func gridShape(windows int) *Shape {
+	cols := int(math.Ceil(math.Sqrt(float64(windows))))
+	rowCount := (windows + cols - 1) / cols
+
+	rows := make([]*Shape, rowCount)
+	for i := range rows {
+		count := windows / rowCount
+		if i >= rowCount-windows%rowCount {
+			count++
+		}
+
+		rows[i] = evenShape(count, bspc.SplitTypeVertical)
+	}
+
+	return chain(rows, bspc.SplitTypeHorizontal)
+}
+
+// evenShape splits the windows in parts of the same size.
+func evenShape(windows int, split bspc.SplitType) *Shape {
+	leaves := make([]*Shape, windows)
+	for i := range leaves {
+		leaves[i] = &Shape{}
+	}
+
+	return chain(leaves, split)
+}
+
+// chain splits the shapes in parts of the same size, each part splitting the rest of the area with the next one.
+func chain(parts []*Shape, split bspc.SplitType) *Shape {
+	if len(parts) == 1 {
+		return parts[0]
+	}
+
+	return &Shape{
+		SplitType:   split,
+		SplitRatio:  1 / float64(len(parts)),
+		FirstChild:  parts[0],
+		SecondChild: chain(parts[1:], split),
+	}
+}
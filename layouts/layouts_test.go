@@ -0,0 +1,116 @@
+package layouts_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/diogox/bspc-go"
+	"github.com/diogox/bspc-go/layouts"
+)
+
+func leaf() *layouts.Shape {
+	return &layouts.Shape{}
+}
+
+func split(t bspc.SplitType, ratio float64, first, second *layouts.Shape) *layouts.Shape {
+	return &layouts.Shape{SplitType: t, SplitRatio: ratio, FirstChild: first, SecondChild: second}
+}
+
+func TestName_Shape(t *testing.T) {
+	const (
+		v = bspc.SplitTypeVertical
+		h = bspc.SplitTypeHorizontal
+	)
+
+	tests := []struct {
+		name    string
+		layout  layouts.Name
+		windows int
+		options layouts.Options
+		want    *layouts.Shape
+	}{
+		{
+			name:    "should have no shape without windows",
+			layout:  layouts.Tall,
+			windows: 0,
+			want:    nil,
+		},
+		{
+			name:    "should give the whole desktop to a single window",
+			layout:  layouts.Grid,
+			windows: 1,
+			want:    leaf(),
+		},
+		{
+			name:    "should stack the windows on the right of the first one, in tall",
+			layout:  layouts.Tall,
+			windows: 3,
+			options: layouts.Options{MasterRatio: 0.6},
+			want:    split(v, 0.6, leaf(), split(h, 0.5, leaf(), leaf())),
+		},
+		{
+			name:    "should stack the windows on the left of the last one, in rtall",
+			layout:  layouts.RTall,
+			windows: 3,
+			options: layouts.Options{MasterRatio: 0.6},
+			want:    split(v, 0.4, split(h, 0.5, leaf(), leaf()), leaf()),
+		},
+		{
+			name:    "should line up the windows below the first one, in wide",
+			layout:  layouts.Wide,
+			windows: 3,
+			want:    split(h, 0.5, leaf(), split(v, 0.5, leaf(), leaf())),
+		},
+		{
+			name:    "should put as many windows in the master area as the master count, in master-stack",
+			layout:  layouts.MasterStack,
+			windows: 3,
+			options: layouts.Options{MasterCount: 2},
+			want:    split(v, 0.5, split(h, 0.5, leaf(), leaf()), leaf()),
+		},
+		{
+			name:    "should stack all the windows while they fit in the master area, in master-stack",
+			layout:  layouts.MasterStack,
+			windows: 2,
+			options: layouts.Options{MasterCount: 2},
+			want:    split(h, 0.5, leaf(), leaf()),
+		},
+		{
+			name:    "should put the extra windows in the last row, in grid",
+			layout:  layouts.Grid,
+			windows: 5,
+			want: split(h, 0.5,
+				split(v, 0.5, leaf(), leaf()),
+				split(v, 1.0/3, leaf(), split(v, 0.5, leaf(), leaf())),
+			),
+		},
+		{
+			name:    "should give columns of the same width to the windows, in even",
+			layout:  layouts.Even,
+			windows: 3,
+			want:    split(v, 1.0/3, leaf(), split(v, 0.5, leaf(), leaf())),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.layout.Shape(tt.windows, tt.options))
+		})
+	}
+}
+
+func TestParseName(t *testing.T) {
+	t.Run("should parse every layout's name", func(t *testing.T) {
+		for _, n := range layouts.Names() {
+			parsed, err := layouts.ParseName(string(n))
+			assert.NoError(t, err)
+			assert.Equal(t, n, parsed)
+		}
+	})
+
+	t.Run("should fail on unknown layouts", func(t *testing.T) {
+		_, err := layouts.ParseName("spiral")
+		assert.ErrorIs(t, err, bspc.ErrInvalidArgument)
+	})
+}
@@ -1,7 +1,6 @@
 package bspc
 
 import (
-	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -25,8 +24,8 @@ type (
 		WindowGap        int       `json:"windowGap"`
 		BorderWidth      int       `json:"borderWidth"`
 		FocusedDesktopID ID        `json:"focusedDesktopId"`
-		Padding          padding   `json:"padding"`
-		Rectangle        rectangle `json:"rectangle"`
+		Padding          Padding   `json:"padding"`
+		Rectangle        Rectangle `json:"rectangle"`
 		Desktops         []Desktop `json:"desktops"`
 	}
 
@@ -39,7 +38,7 @@ type (
 		WindowGap     int        `json:"windowGap"`
 		BorderWidth   int        `json:"borderWidth"`
 		FocusedNodeID ID         `json:"focusedNodeId"`
-		Padding       padding    `json:"padding"`
+		Padding       Padding    `json:"padding"`
 		Root          Node       `json:"root"`
 	}
 
@@ -49,72 +48,25 @@ type (
 
 		// Payload needs to be type-cast into an event struct, according to the event type above.
 		Payload interface{}
-	}
-
-	padding struct {
-		Top    int `json:"top"`
-		Right  int `json:"right"`
-		Bottom int `json:"bottom"`
-		Left   int `json:"left"`
-	}
 
-	rectangle struct {
-		X      int `json:"x"`
-		Y      int `json:"Y"`
-		Width  int `json:"width"`
-		Height int `json:"height"`
-	}
-
-	constraints struct {
-		MinWidth  int `json:"min_width"`
-		MinHeight int `json:"min_height"`
+		// Display is the name of the display whose bspwm instance published the event.
+		// It is only set for events received through a Registry.
+		Display string
 	}
 )
 
-func hexToID(hex string) (ID, error) {
-	id, err := strconv.ParseUint(strings.Replace(hex, "x0", "", 1), 16, 32)
-	if err != nil {
-		return 0, fmt.Errorf("failed to parse hex to ID: %v", err)
-	}
-
-	return ID(id), nil
-}
-
-func geometryToRectangle(geometry string) (rectangle, error) {
-	geometryParts := strings.Split(geometry, "+")
-	if len(geometryParts) != 3 {
-		return rectangle{}, errors.New("not enough fields for monitor geometry")
+// ParseID parses an ID the way bspwm prints it: in hexadecimal, prefixed with "0x".
+// Decimal IDs, which is how bspwm passes them to the external rules command, are also accepted.
+func ParseID(s string) (ID, error) {
+	base := 10
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		s, base = s[2:], 16
 	}
 
-	geometryResolution := strings.Split(geometryParts[0], "x")
-	if len(geometryParts) != 2 {
-		return rectangle{}, errors.New("not enough fields for monitor geometry resolution")
-	}
-
-	geometryX, err := strconv.Atoi(geometryParts[1])
-	if err != nil {
-		return rectangle{}, fmt.Errorf("monitor geometry X not a number: %v", err)
-	}
-
-	geometryY, err := strconv.Atoi(geometryParts[2])
+	id, err := strconv.ParseUint(s, base, 32)
 	if err != nil {
-		return rectangle{}, fmt.Errorf("monitor geometry Y not a number: %v", err)
+		return 0, fmt.Errorf("failed to parse ID: %v", err)
 	}
 
-	geometryWidth, err := strconv.Atoi(geometryResolution[0])
-	if err != nil {
-		return rectangle{}, fmt.Errorf("monitor geometry width not a number: %v", err)
-	}
-
-	geometryHeight, err := strconv.Atoi(geometryResolution[1])
-	if err != nil {
-		return rectangle{}, fmt.Errorf("monitor geometry height not a number: %v", err)
-	}
-
-	return rectangle{
-		X:      geometryX,
-		Y:      geometryY,
-		Width:  geometryWidth,
-		Height: geometryHeight,
-	}, nil
+	return ID(id), nil
 }
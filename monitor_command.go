@@ -0,0 +1,114 @@
+package bspc
+
+import (
+	"context"
+	"fmt"
+)
+
+// MonitorCommand builds and sends `monitor` commands for the monitors matching its selector.
+// Each method sends a single command to bspwm, and returns a *CommandError if bspwm rejects it.
+// Arguments are validated before being sent, in which case the returned error matches ErrInvalidArgument.
+type MonitorCommand struct {
+	client   client
+	ctx      context.Context
+	selector Selector
+}
+
+// Monitor returns a builder for the `monitor` commands, applied to the monitors matching the selector.
+// The zero selector targets the focused monitor.
+func (c client) Monitor(sel Selector) MonitorCommand {
+	return MonitorCommand{
+		client:   c,
+		ctx:      context.Background(),
+		selector: sel,
+	}
+}
+
+// WithContext returns a copy of the builder, that sends its commands under the given context.
+func (mc MonitorCommand) WithContext(ctx context.Context) MonitorCommand {
+	mc.ctx = ctx
+	return mc
+}
+
+// Focus focuses the monitor.
+func (mc MonitorCommand) Focus() error {
+	return mc.send("--focus")
+}
+
+// Swap swaps the monitor with the monitor matching the selector.
+func (mc MonitorCommand) Swap(monitorSel Selector) error {
+	return mc.send("--swap", monitorSel.String())
+}
+
+// AddDesktops creates new desktops with the given names, in the monitor.
+func (mc MonitorCommand) AddDesktops(names ...string) error {
+	if err := validateDesktopNames(names); err != nil {
+		return err
+	}
+
+	return mc.send(append([]string{"--add-desktops"}, names...)...)
+}
+
+// ReorderDesktops renames, and thereby reorders, the existing desktops of the monitor.
+func (mc MonitorCommand) ReorderDesktops(names ...string) error {
+	if err := validateDesktopNames(names); err != nil {
+		return err
+	}
+
+	return mc.send(append([]string{"--reorder-desktops"}, names...)...)
+}
+
+// ResetDesktops renames, adds or removes desktops of the monitor, so that it ends up with the given names.
+func (mc MonitorCommand) ResetDesktops(names ...string) error {
+	if err := validateDesktopNames(names); err != nil {
+		return err
+	}
+
+	return mc.send(append([]string{"--reset-desktops"}, names...)...)
+}
+
+// Rectangle sets the rectangle of the monitor.
+func (mc MonitorCommand) Rectangle(r Rectangle) error {
+	if r.IsEmpty() {
+		return fmt.Errorf("%w: invalid rectangle %s", ErrInvalidArgument, r.String())
+	}
+
+	return mc.send("--rectangle", r.String())
+}
+
+// Rename renames the monitor.
+func (mc MonitorCommand) Rename(name string) error {
+	if name == "" {
+		return fmt.Errorf("%w: empty monitor name", ErrInvalidArgument)
+	}
+
+	return mc.send("--rename", name)
+}
+
+// Remove removes the monitor.
+func (mc MonitorCommand) Remove() error {
+	return mc.send("--remove")
+}
+
+func (mc MonitorCommand) send(args ...string) error {
+	cmd := ipcCommand{"monitor"}
+	if !mc.selector.IsZero() {
+		cmd = append(cmd, mc.selector.String())
+	}
+
+	return mc.client.query(mc.ctx, append(cmd, args...), nil)
+}
+
+func validateDesktopNames(names []string) error {
+	if len(names) == 0 {
+		return fmt.Errorf("%w: no desktop names", ErrInvalidArgument)
+	}
+
+	for _, n := range names {
+		if n == "" {
+			return fmt.Errorf("%w: empty desktop name", ErrInvalidArgument)
+		}
+	}
+
+	return nil
+}
@@ -1,5 +1,7 @@
 package bspc
 
+import "fmt"
+
 type (
 	// Node contains all the info regarding a given node.
 	Node struct {
@@ -13,8 +15,8 @@ type (
 		Locked      bool           `json:"locked"`
 		Marked      bool           `json:"marked"`
 		Preselect   *NodePreselect `json:"presel"`
-		Rectangle   rectangle      `json:"rectangle"`
-		Constraints constraints    `json:"constraints"`
+		Rectangle   Rectangle      `json:"rectangle"`
+		Constraints Constraints    `json:"constraints"`
 		FirstChild  *Node          `json:"firstChild"`
 		SecondChild *Node          `json:"secondChild"`
 		Client      *NodeClient    `json:"client"`
@@ -27,18 +29,19 @@ type (
 	}
 
 	// NodeClient contains all the info regarding a node's client. The program it contains.
+	// Its states and layers are only validated when decoded through ToNodeTree.
 	NodeClient struct {
 		ClassName         string    `json:"className"`
 		InstanceName      string    `json:"instanceName"`
 		BorderWidth       int       `json:"borderWidth"`
-		State             StateType `json:"state"`     // TODO: Add validation for this in the GetState method
-		LastState         StateType `json:"lastState"` // TODO: Add validation for this in the GetState method
-		Layer             LayerType `json:"layer"`     // TODO: Add validation for this in the GetState method
-		LastLayer         LayerType `json:"lastLayer"` // TODO: Add validation for this in the GetState method
+		State             StateType `json:"state"`
+		LastState         StateType `json:"lastState"`
+		Layer             LayerType `json:"layer"`
+		LastLayer         LayerType `json:"lastLayer"`
 		Urgent            bool      `json:"urgent"`
 		Shown             bool      `json:"shown"`
-		TiledRectangle    rectangle `json:"tiledRectangle"`
-		FloatingRectangle rectangle `json:"floatingRectangle"`
+		TiledRectangle    Rectangle `json:"tiledRectangle"`
+		FloatingRectangle Rectangle `json:"floatingRectangle"`
 	}
 )
 
@@ -66,3 +69,40 @@ func (n Node) LeafNodes() []Node {
 
 	return leafNodes
 }
+
+// validate returns an error if any of the enums in the tree holds a value bspwm doesn't know of.
+func (n Node) validate() error {
+	if !n.SplitType.IsValid() {
+		return fmt.Errorf("node 0x%08X has an invalid split type: %s", uint(n.ID), n.SplitType)
+	}
+
+	if n.Preselect != nil && !n.Preselect.SplitDirection.IsValid() {
+		return fmt.Errorf("node 0x%08X has an invalid presel direction: %s", uint(n.ID), n.Preselect.SplitDirection)
+	}
+
+	if c := n.Client; c != nil {
+		for _, st := range []StateType{c.State, c.LastState} {
+			if !st.IsValid() {
+				return fmt.Errorf("node 0x%08X has an invalid state: %s", uint(n.ID), st)
+			}
+		}
+
+		for _, lt := range []LayerType{c.Layer, c.LastLayer} {
+			if !lt.IsValid() {
+				return fmt.Errorf("node 0x%08X has an invalid layer: %s", uint(n.ID), lt)
+			}
+		}
+	}
+
+	for _, child := range []*Node{n.FirstChild, n.SecondChild} {
+		if child == nil {
+			continue
+		}
+
+		if err := child.validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,221 @@
+package bspc
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// NodeCommand builds and sends `node` commands for the nodes matching its selector.
+// Each method sends a single command to bspwm, and returns a *CommandError if bspwm rejects it.
+// Arguments are validated before being sent, in which case the returned error matches ErrInvalidArgument.
+type NodeCommand struct {
+	client   client
+	ctx      context.Context
+	selector Selector
+}
+
+// Node returns a builder for the `node` commands, applied to the nodes matching the selector.
+// The zero selector targets the focused node.
+func (c client) Node(sel Selector) NodeCommand {
+	return NodeCommand{
+		client:   c,
+		ctx:      context.Background(),
+		selector: sel,
+	}
+}
+
+// WithContext returns a copy of the builder, that sends its commands under the given context.
+func (nc NodeCommand) WithContext(ctx context.Context) NodeCommand {
+	nc.ctx = ctx
+	return nc
+}
+
+// Focus focuses the node.
+func (nc NodeCommand) Focus() error {
+	return nc.send("--focus")
+}
+
+// Activate activates the node, without focusing it.
+func (nc NodeCommand) Activate() error {
+	return nc.send("--activate")
+}
+
+// ToDesktop sends the node to the desktop matching the selector.
+// If follow is true, the focus follows the node.
+func (nc NodeCommand) ToDesktop(desktopSel Selector, follow bool) error {
+	return nc.send(withFollow([]string{"--to-desktop", desktopSel.String()}, follow)...)
+}
+
+// ToMonitor sends the node to the monitor matching the selector.
+// If follow is true, the focus follows the node.
+func (nc NodeCommand) ToMonitor(monitorSel Selector, follow bool) error {
+	return nc.send(withFollow([]string{"--to-monitor", monitorSel.String()}, follow)...)
+}
+
+// ToNode transplants the node into the node matching the selector.
+// If follow is true, the focus follows the node.
+func (nc NodeCommand) ToNode(nodeSel Selector, follow bool) error {
+	return nc.send(withFollow([]string{"--to-node", nodeSel.String()}, follow)...)
+}
+
+// Swap swaps the node with the node matching the selector.
+func (nc NodeCommand) Swap(nodeSel Selector) error {
+	return nc.send("--swap", nodeSel.String())
+}
+
+// Presel preselects the splitting area of the node, in the given direction.
+func (nc NodeCommand) Presel(dir DirectionType) error {
+	if !dir.IsValid() {
+		return fmt.Errorf("%w: invalid direction %s", ErrInvalidArgument, dir)
+	}
+
+	return nc.send("--presel-dir", string(dir))
+}
+
+// PreselRatio sets the splitting ratio of the node's preselection.
+func (nc NodeCommand) PreselRatio(ratio float64) error {
+	if !isValidRatio(ratio) {
+		return fmt.Errorf("%w: invalid ratio %v", ErrInvalidArgument, ratio)
+	}
+
+	return nc.send("--presel-ratio", formatFloat(ratio))
+}
+
+// PreselCancel cancels the node's preselection.
+func (nc NodeCommand) PreselCancel() error {
+	return nc.send("--presel-dir", "cancel")
+}
+
+// Ratio sets the splitting ratio of the node.
+func (nc NodeCommand) Ratio(ratio float64) error {
+	if !isValidRatio(ratio) {
+		return fmt.Errorf("%w: invalid ratio %v", ErrInvalidArgument, ratio)
+	}
+
+	return nc.send("--ratio", formatFloat(ratio))
+}
+
+// Resize resizes the node, by moving the given handle by dx and dy pixels.
+func (nc NodeCommand) Resize(handle HandleType, dx, dy int) error {
+	if !handle.IsValid() {
+		return fmt.Errorf("%w: invalid handle %s", ErrInvalidArgument, handle)
+	}
+
+	return nc.send("--resize", string(handle), strconv.Itoa(dx), strconv.Itoa(dy))
+}
+
+// Move moves the floating node by dx and dy pixels.
+func (nc NodeCommand) Move(dx, dy int) error {
+	return nc.send("--move", strconv.Itoa(dx), strconv.Itoa(dy))
+}
+
+// State sets the state of the node.
+func (nc NodeCommand) State(state StateType) error {
+	if !state.IsValid() {
+		return fmt.Errorf("%w: invalid state %s", ErrInvalidArgument, state)
+	}
+
+	return nc.send("--state", string(state))
+}
+
+// Flag turns the given flag on or off, for the node.
+// The urgent flag is only set by the windows themselves, so bspwm doesn't accept it.
+func (nc NodeCommand) Flag(flag FlagType, enabled bool) error {
+	if !flag.IsValid() || flag == FlagTypeUrgent {
+		return fmt.Errorf("%w: invalid flag %s", ErrInvalidArgument, flag)
+	}
+
+	value := "off"
+	if enabled {
+		value = "on"
+	}
+
+	return nc.send("--flag", string(flag)+"="+value)
+}
+
+// Layer sets the stacking layer of the node.
+func (nc NodeCommand) Layer(layer LayerType) error {
+	if !layer.IsValid() {
+		return fmt.Errorf("%w: invalid layer %s", ErrInvalidArgument, layer)
+	}
+
+	return nc.send("--layer", string(layer))
+}
+
+// Rotate rotates the tree rooted at the node, clockwise.
+func (nc NodeCommand) Rotate(angle RotationType) error {
+	if !angle.IsValid() {
+		return fmt.Errorf("%w: invalid rotation %s", ErrInvalidArgument, angle)
+	}
+
+	return nc.send("--rotate", string(angle))
+}
+
+// Flip flips the tree rooted at the node.
+func (nc NodeCommand) Flip(split SplitType) error {
+	if !split.IsValid() {
+		return fmt.Errorf("%w: invalid flip %s", ErrInvalidArgument, split)
+	}
+
+	return nc.send("--flip", string(split))
+}
+
+// Equalize resets the split ratios of the tree rooted at the node, to their default value.
+func (nc NodeCommand) Equalize() error {
+	return nc.send("--equalize")
+}
+
+// Balance adjusts the split ratios of the tree rooted at the node, so that all windows occupy the same area.
+func (nc NodeCommand) Balance() error {
+	return nc.send("--balance")
+}
+
+// Circulate circulates the windows of the tree rooted at the node.
+func (nc NodeCommand) Circulate(dir CirculateDirType) error {
+	if !dir.IsValid() {
+		return fmt.Errorf("%w: invalid circulation direction %s", ErrInvalidArgument, dir)
+	}
+
+	return nc.send("--circulate", string(dir))
+}
+
+// InsertReceptacle inserts a receptacle node at the node.
+func (nc NodeCommand) InsertReceptacle() error {
+	return nc.send("--insert-receptacle")
+}
+
+// Close closes the windows of the tree rooted at the node.
+func (nc NodeCommand) Close() error {
+	return nc.send("--close")
+}
+
+// Kill kills the windows of the tree rooted at the node.
+func (nc NodeCommand) Kill() error {
+	return nc.send("--kill")
+}
+
+func (nc NodeCommand) send(args ...string) error {
+	cmd := ipcCommand{"node"}
+	if !nc.selector.IsZero() {
+		cmd = append(cmd, nc.selector.String())
+	}
+
+	return nc.client.query(nc.ctx, append(cmd, args...), nil)
+}
+
+func withFollow(args []string, follow bool) []string {
+	if follow {
+		return append(args, "--follow")
+	}
+
+	return args
+}
+
+func isValidRatio(ratio float64) bool {
+	return ratio > 0 && ratio < 1
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
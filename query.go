@@ -1,19 +1,27 @@
 package bspc
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 )
 
+// QueryResponseResolver decodes the payload of a successful response. See the To* functions for the ones
+// this package provides.
 type QueryResponseResolver func(payload []byte) error
 
-// TODO: godoc
-// TODO: restrict this somehow so that it only accepts structs?
-//   Maybe I can have an interface implemented by all the package's types, and that is required here.
+// ToStruct decodes a JSON response into the value res points to.
+// It panics if res isn't a non-nil pointer, as decoding into a copy would silently discard the response.
 func ToStruct(res interface{}) QueryResponseResolver {
+	if rv := reflect.ValueOf(res); rv.Kind() != reflect.Ptr || rv.IsNil() {
+		panic(fmt.Sprintf("bspc: ToStruct requires a non-nil pointer, got %T", res))
+	}
+
 	return func(payload []byte) error {
-		if err := json.Unmarshal(payload, &res); err != nil {
+		if err := json.Unmarshal(payload, res); err != nil {
 			return err
 		}
 
@@ -21,9 +29,34 @@ func ToStruct(res interface{}) QueryResponseResolver {
 	}
 }
 
+// ToRaw copies the response, as is.
+func ToRaw(res *[]byte) QueryResponseResolver {
+	return func(payload []byte) error {
+		*res = append((*res)[:0], payload...)
+		return nil
+	}
+}
+
+// ToLines calls fn with each non-empty line of the response, in order, and stops at the first error it returns.
+func ToLines(fn func(line string) error) QueryResponseResolver {
+	return func(payload []byte) error {
+		for _, l := range strings.Split(string(payload), "\n") {
+			if l == "" {
+				continue
+			}
+
+			if err := fn(l); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
 func ToID(res *ID) QueryResponseResolver {
 	return func(payload []byte) error {
-		id, err := hexToID(strings.ReplaceAll(string(payload), "\n", ""))
+		id, err := ParseID(strings.ReplaceAll(string(payload), "\n", ""))
 		if err != nil {
 			return fmt.Errorf("failed to convert hex iD into ID type: %v", err)
 		}
@@ -35,21 +68,230 @@ func ToID(res *ID) QueryResponseResolver {
 }
 
 func ToIDSlice(res *[]ID) QueryResponseResolver {
+	return ToLines(func(l string) error {
+		id, err := ParseID(l)
+		if err != nil {
+			return fmt.Errorf("failed to convert hex iD into ID type: %v", err)
+		}
+
+		*res = append(*res, id)
+
+		return nil
+	})
+}
+
+// ToNames populates the names in a `--names` response, one per line.
+func ToNames(res *[]string) QueryResponseResolver {
+	return ToLines(func(l string) error {
+		*res = append(*res, l)
+		return nil
+	})
+}
+
+// ToNodeTree decodes the response of `query --tree --node`, and checks that its enums hold values bspwm knows of.
+func ToNodeTree(res *Node) QueryResponseResolver {
 	return func(payload []byte) error {
-		lines := strings.Split(string(payload), "\n")
-		for _, l := range lines {
-			if l == "" {
-				continue
-			}
+		var n Node
+		if err := json.Unmarshal(payload, &n); err != nil {
+			return err
+		}
 
-			id, err := hexToID(l)
-			if err != nil {
-				return fmt.Errorf("failed to convert hex iD into ID type: %v", err)
-			}
+		if err := n.validate(); err != nil {
+			return err
+		}
+
+		*res = n
 
-			*res = append(*res, id)
+		return nil
+	}
+}
+
+// ToMonitorRectangle decodes the rectangle of the monitor, out of the response of `query --tree --monitor`.
+func ToMonitorRectangle(res *Rectangle) QueryResponseResolver {
+	return func(payload []byte) error {
+		var m struct {
+			Rectangle *Rectangle `json:"rectangle"`
 		}
 
+		if err := json.Unmarshal(payload, &m); err != nil {
+			return err
+		}
+
+		if m.Rectangle == nil {
+			return errors.New("monitor has no rectangle")
+		}
+
+		*res = *m.Rectangle
+
+		return nil
+	}
+}
+
+// QueryNodes returns the IDs of the nodes matching the selector.
+// An empty slice is returned when nothing matches.
+func (c client) QueryNodes(sel Selector) ([]ID, error) {
+	return c.QueryNodesContext(context.Background(), sel)
+}
+
+// QueryNodesContext works like QueryNodes, but gives up waiting on bspwm once the context is done.
+func (c client) QueryNodesContext(ctx context.Context, sel Selector) ([]ID, error) {
+	ids := make([]ID, 0)
+	if err := c.queryDomain(ctx, "--nodes", "--node", sel, nil, ToIDSlice(&ids)); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// QueryDesktops returns the IDs of the desktops matching the selector.
+// An empty slice is returned when nothing matches.
+func (c client) QueryDesktops(sel Selector) ([]ID, error) {
+	return c.QueryDesktopsContext(context.Background(), sel)
+}
+
+// QueryDesktopsContext works like QueryDesktops, but gives up waiting on bspwm once the context is done.
+func (c client) QueryDesktopsContext(ctx context.Context, sel Selector) ([]ID, error) {
+	ids := make([]ID, 0)
+	if err := c.queryDomain(ctx, "--desktops", "--desktop", sel, nil, ToIDSlice(&ids)); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// QueryMonitors returns the IDs of the monitors matching the selector.
+// An empty slice is returned when nothing matches.
+func (c client) QueryMonitors(sel Selector) ([]ID, error) {
+	return c.QueryMonitorsContext(context.Background(), sel)
+}
+
+// QueryMonitorsContext works like QueryMonitors, but gives up waiting on bspwm once the context is done.
+func (c client) QueryMonitorsContext(ctx context.Context, sel Selector) ([]ID, error) {
+	ids := make([]ID, 0)
+	if err := c.queryDomain(ctx, "--monitors", "--monitor", sel, nil, ToIDSlice(&ids)); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// QueryDesktopNames returns the names of the desktops matching the selector.
+// An empty slice is returned when nothing matches.
+func (c client) QueryDesktopNames(sel Selector) ([]string, error) {
+	return c.QueryDesktopNamesContext(context.Background(), sel)
+}
+
+// QueryDesktopNamesContext works like QueryDesktopNames, but gives up waiting on bspwm once the context is done.
+func (c client) QueryDesktopNamesContext(ctx context.Context, sel Selector) ([]string, error) {
+	names := make([]string, 0)
+	if err := c.queryDomain(ctx, "--desktops", "--desktop", sel, []string{"--names"}, ToNames(&names)); err != nil {
+		return nil, err
+	}
+
+	return names, nil
+}
+
+// QueryMonitorNames returns the names of the monitors matching the selector.
+// An empty slice is returned when nothing matches.
+func (c client) QueryMonitorNames(sel Selector) ([]string, error) {
+	return c.QueryMonitorNamesContext(context.Background(), sel)
+}
+
+// QueryMonitorNamesContext works like QueryMonitorNames, but gives up waiting on bspwm once the context is done.
+func (c client) QueryMonitorNamesContext(ctx context.Context, sel Selector) ([]string, error) {
+	names := make([]string, 0)
+	if err := c.queryDomain(ctx, "--monitors", "--monitor", sel, []string{"--names"}, ToNames(&names)); err != nil {
+		return nil, err
+	}
+
+	return names, nil
+}
+
+// Tree returns the tree rooted at the node matching the selector.
+func (c client) Tree(sel Selector) (Node, error) {
+	return c.TreeContext(context.Background(), sel)
+}
+
+// TreeContext works like Tree, but gives up waiting on bspwm once the context is done.
+func (c client) TreeContext(ctx context.Context, sel Selector) (Node, error) {
+	var n Node
+	if err := c.query(ctx, treeCommand("--node", sel), ToNodeTree(&n)); err != nil {
+		return Node{}, err
+	}
+
+	return n, nil
+}
+
+// DesktopTree returns the desktop matching the selector, along with its tree.
+func (c client) DesktopTree(sel Selector) (Desktop, error) {
+	return c.DesktopTreeContext(context.Background(), sel)
+}
+
+// DesktopTreeContext works like DesktopTree, but gives up waiting on bspwm once the context is done.
+func (c client) DesktopTreeContext(ctx context.Context, sel Selector) (Desktop, error) {
+	var d Desktop
+	if err := c.query(ctx, treeCommand("--desktop", sel), ToStruct(&d)); err != nil {
+		return Desktop{}, err
+	}
+
+	return d, nil
+}
+
+// MonitorTree returns the monitor matching the selector, along with its desktops.
+func (c client) MonitorTree(sel Selector) (Monitor, error) {
+	return c.MonitorTreeContext(context.Background(), sel)
+}
+
+// MonitorTreeContext works like MonitorTree, but gives up waiting on bspwm once the context is done.
+func (c client) MonitorTreeContext(ctx context.Context, sel Selector) (Monitor, error) {
+	var m Monitor
+	if err := c.query(ctx, treeCommand("--monitor", sel), ToStruct(&m)); err != nil {
+		return Monitor{}, err
+	}
+
+	return m, nil
+}
+
+// DumpState returns the whole state of bspwm.
+func (c client) DumpState() (State, error) {
+	return c.DumpStateContext(context.Background())
+}
+
+// DumpStateContext works like DumpState, but gives up waiting on bspwm once the context is done.
+func (c client) DumpStateContext(ctx context.Context) (State, error) {
+	var st State
+	if err := c.query(ctx, ipcCommand{"wm", "--dump-state"}, ToStruct(&st)); err != nil {
+		return State{}, err
+	}
+
+	return st, nil
+}
+
+// queryDomain lists the elements of a domain, narrowed down by the selector if it isn't empty.
+// bspwm reports a failure with no message when nothing matches, which is treated as an empty response.
+func (c client) queryDomain(ctx context.Context, domainFlag, selFlag string, sel Selector, extraArgs []string, resResolver QueryResponseResolver) error {
+	cmd := ipcCommand{"query", domainFlag}
+	if !sel.IsZero() {
+		cmd = append(cmd, selFlag, sel.String())
+	}
+
+	err := c.query(ctx, append(cmd, extraArgs...), resResolver)
+
+	var cmdErr *CommandError
+	if errors.As(err, &cmdErr) && cmdErr.Message == "" {
 		return nil
 	}
+
+	return err
+}
+
+// treeCommand queries the tree of the item matching the selector. bspwm needs the domain flag to know which
+// item to dump, and picks the focused one when it's given no selector.
+func treeCommand(selFlag string, sel Selector) ipcCommand {
+	cmd := ipcCommand{"query", "--tree", selFlag}
+	if !sel.IsZero() {
+		cmd = append(cmd, sel.String())
+	}
+
+	return cmd
 }
@@ -0,0 +1,69 @@
+package bspc_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diogox/bspc-go"
+)
+
+func TestToStruct(t *testing.T) {
+	t.Run("should reject non-pointers at construction", func(t *testing.T) {
+		assert.Panics(t, func() { bspc.ToStruct(bspc.State{}) })
+		assert.Panics(t, func() { bspc.ToStruct((*bspc.State)(nil)) })
+	})
+}
+
+func TestToLines(t *testing.T) {
+	t.Run("should skip empty lines and stop at the first error", func(t *testing.T) {
+		var lines []string
+		errStop := errors.New("stop")
+
+		err := bspc.ToLines(func(l string) error {
+			if l == "stop" {
+				return errStop
+			}
+
+			lines = append(lines, l)
+
+			return nil
+		})([]byte("I\n\nII\nstop\nIII\n"))
+
+		assert.Equal(t, errStop, err)
+		assert.Equal(t, []string{"I", "II"}, lines)
+	})
+}
+
+func TestToNodeTree(t *testing.T) {
+	const leaf = `{"id":14680067,"splitType":"vertical","client":{"className":"mpv","state":"%s","lastState":"tiled","layer":"normal","lastLayer":"normal"}}`
+
+	t.Run("should decode valid trees", func(t *testing.T) {
+		var n bspc.Node
+		require.NoError(t, bspc.ToNodeTree(&n)([]byte(`{"id":4194305,"splitType":"horizontal","firstChild":`+
+			fmt.Sprintf(leaf, "floating")+`}`)))
+
+		assert.Equal(t, bspc.StateTypeFloating, n.FirstChild.Client.State)
+	})
+
+	t.Run("should reject unknown enum values, however deep", func(t *testing.T) {
+		n := bspc.Node{ID: 1}
+		err := bspc.ToNodeTree(&n)([]byte(`{"id":4194305,"splitType":"horizontal","firstChild":` + fmt.Sprintf(leaf, "sideways") + `}`))
+
+		assert.Error(t, err)
+		assert.Equal(t, bspc.Node{ID: 1}, n)
+	})
+}
+
+func TestToMonitorRectangle(t *testing.T) {
+	t.Run("should decode the monitor's rectangle", func(t *testing.T) {
+		var m bspc.Monitor
+		require.NoError(t, bspc.ToMonitorRectangle(&m.Rectangle)([]byte(`{"name":"eDP-1","rectangle":{"x":0,"y":0,"width":1920,"height":1080}}`)))
+
+		assert.Equal(t, 1920, m.Rectangle.Width)
+		assert.Error(t, bspc.ToMonitorRectangle(&m.Rectangle)([]byte(`{"name":"eDP-1"}`)))
+	})
+}
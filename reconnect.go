@@ -0,0 +1,136 @@
+package bspc
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	defaultInitialBackoff = 100 * time.Millisecond
+	defaultMaxBackoff     = 5 * time.Second
+)
+
+// ReconnectOptions configures how SubscribeEventsWithReconnect waits between connection attempts.
+// The wait starts at InitialBackoff, and doubles after each attempt, up to MaxBackoff.
+// It only goes back to InitialBackoff once a subscription delivers an event.
+// Zero values are replaced with sensible defaults.
+type ReconnectOptions struct {
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// SubscribeEventsWithReconnect works like the client's SubscribeEventsContext, but survives bspwm
+// restarting or dropping the connection: the same subscription is made again, with backoff, until it succeeds.
+// Every time that happens, an EventTypeReconnected event is sent, so that consumers can dump the state again,
+// as they might have missed some events in between.
+// The errors that ended each subscription are sent into the errors channel, which needs to be drained.
+// A *CommandError means that bspwm rejected the subscription, which it would do again, so it's sent and
+// both channels are closed. Otherwise, they're only closed once the context is done.
+func SubscribeEventsWithReconnect(ctx context.Context, c Client, opts ReconnectOptions, event EventType, moreEvents ...EventType) (chan Event, chan error, error) {
+	if opts.InitialBackoff <= 0 {
+		opts.InitialBackoff = defaultInitialBackoff
+	}
+
+	if opts.MaxBackoff < opts.InitialBackoff {
+		opts.MaxBackoff = defaultMaxBackoff
+		if opts.MaxBackoff < opts.InitialBackoff {
+			opts.MaxBackoff = opts.InitialBackoff
+		}
+	}
+
+	eventCh, errCh, err := c.SubscribeEventsContext(ctx, event, moreEvents...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		eventsChannel = make(chan Event)
+		errorsChannel = make(chan error)
+	)
+
+	go func() {
+		defer func() {
+			close(eventsChannel)
+			close(errorsChannel)
+		}()
+
+		backoff := opts.InitialBackoff
+		for {
+			delivered, err := forwardEvents(ctx, eventCh, errCh, eventsChannel)
+			if ctx.Err() != nil {
+				return
+			}
+
+			select {
+			case errorsChannel <- err:
+			case <-ctx.Done():
+				return
+			}
+
+			var cmdErr *CommandError
+			if errors.As(err, &cmdErr) {
+				return
+			}
+
+			// Subscriptions that end before delivering anything keep backing off, so that they can't spin.
+			if delivered {
+				backoff = opts.InitialBackoff
+			}
+
+			for attempt := 1; ; attempt++ {
+				select {
+				case <-time.After(backoff):
+				case <-ctx.Done():
+					return
+				}
+
+				if backoff *= 2; backoff > opts.MaxBackoff {
+					backoff = opts.MaxBackoff
+				}
+
+				eventCh, errCh, err = c.SubscribeEventsContext(ctx, event, moreEvents...)
+				if err == nil {
+					ev := Event{Type: EventTypeReconnected, Payload: EventReconnected{Attempts: attempt}}
+
+					select {
+					case eventsChannel <- ev:
+					case <-ctx.Done():
+						return
+					}
+
+					break
+				}
+			}
+		}
+	}()
+
+	return eventsChannel, errorsChannel, nil
+}
+
+// forwardEvents sends the subscription's events into the channel, until the subscription ends.
+// It returns whether any event was sent, and the error that ended it, which is never nil unless the context is done.
+func forwardEvents(ctx context.Context, eventCh chan Event, errCh chan error, out chan Event) (bool, error) {
+	var delivered bool
+	for {
+		select {
+		case ev, ok := <-eventCh:
+			if !ok {
+				if err, ok := <-errCh; ok {
+					return delivered, err
+				}
+
+				return delivered, ErrSubscriptionEnded
+			}
+
+			select {
+			case out <- ev:
+				delivered = true
+			case <-ctx.Done():
+				return delivered, nil
+			}
+		case <-ctx.Done():
+			return delivered, nil
+		}
+	}
+}
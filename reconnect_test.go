@@ -0,0 +1,72 @@
+package bspc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diogox/bspc-go"
+)
+
+func TestSubscribeEventsWithReconnect(t *testing.T) {
+	t.Run("should subscribe again after the connection drops", func(t *testing.T) {
+		c, srv := newTestClient(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		opts := bspc.ReconnectOptions{InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+
+		eventCh, errCh, err := bspc.SubscribeEventsWithReconnect(ctx, c, opts, bspc.EventTypeMonitorFocus, bspc.EventTypeDesktopFocus)
+		require.NoError(t, err)
+
+		srv.WaitForSubscribers(1)
+		srv.DisconnectSubscribers()
+
+		assert.Error(t, <-errCh)
+
+		ev := <-eventCh
+		assert.Equal(t, bspc.EventTypeReconnected, ev.Type)
+		assert.Equal(t, bspc.EventReconnected{Attempts: 1}, ev.Payload)
+
+		srv.WaitForSubscribers(1)
+		srv.Publish("monitor_focus 0x00200002")
+
+		ev = <-eventCh
+		assert.Equal(t, bspc.EventMonitorFocus{MonitorID: 0x00200002}, ev.Payload)
+		assert.Equal(t, []string{
+			"subscribe monitor_focus desktop_focus",
+			"subscribe monitor_focus desktop_focus",
+		}, srv.Commands())
+
+		cancel()
+		_, ok := <-eventCh
+		assert.False(t, ok)
+	})
+
+	t.Run("should give up once bspwm rejects the subscription", func(t *testing.T) {
+		c, srv := newTestClient(t)
+		srv.Fail("subscribe node_focus", "subscribe: Invalid argument: 'node_focus'.")
+
+		opts := bspc.ReconnectOptions{InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+		eventCh, errCh, err := bspc.SubscribeEventsWithReconnect(context.Background(), c, opts, bspc.EventTypeNodeFocus)
+		require.NoError(t, err)
+
+		err = <-errCh
+		var cmdErr *bspc.CommandError
+		assert.True(t, errors.As(err, &cmdErr), err)
+		assert.True(t, errors.Is(err, bspc.ErrInvalidArgument))
+
+		_, ok := <-eventCh
+		assert.False(t, ok)
+		_, ok = <-errCh
+		assert.False(t, ok)
+
+		assert.Equal(t, []string{"subscribe node_focus"}, srv.Commands())
+	})
+}
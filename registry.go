@@ -0,0 +1,168 @@
+package bspc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrUnknownDisplay is returned when the registry has no bspwm instance for a display.
+var ErrUnknownDisplay = errors.New("no bspwm instance for display")
+
+// Registry holds a client for each of several bspwm instances, one per X display.
+type Registry struct {
+	displays []Display
+	clients  map[Display]Client
+}
+
+// NewRegistry returns a registry with a client for every bspwm instance running on this machine.
+// Take a look at DiscoverSockets to know more.
+// If the value passed in as a logger is nil, logging will be disabled.
+func NewRegistry(logger Logger) (*Registry, error) {
+	sockets, err := DiscoverSockets()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRegistryWithSockets(sockets, logger)
+}
+
+// NewRegistryWithSockets returns a registry with a client for each of the given sockets.
+// If the value passed in as a logger is nil, logging will be disabled.
+func NewRegistryWithSockets(sockets []Socket, logger Logger) (*Registry, error) {
+	r := &Registry{
+		clients: make(map[Display]Client, len(sockets)),
+	}
+
+	for _, s := range sockets {
+		if _, ok := r.clients[s.Display]; ok {
+			return nil, fmt.Errorf("more than one socket for display %s", s.Display)
+		}
+
+		c, err := NewWithSocketPath(s.Path, logger)
+		if err != nil {
+			return nil, fmt.Errorf("display %s: %w", s.Display, err)
+		}
+
+		r.displays = append(r.displays, s.Display)
+		r.clients[s.Display] = c
+	}
+
+	return r, nil
+}
+
+// Displays returns the displays of every instance in the registry.
+func (r *Registry) Displays() []Display {
+	return append([]Display(nil), r.displays...)
+}
+
+// Client returns the client for the instance managing the display, given its name, e.g. ":1".
+func (r *Registry) Client(displayName string) (Client, error) {
+	d, err := ParseDisplay(displayName)
+	if err != nil {
+		return nil, err
+	}
+
+	c, ok := r.clients[d]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownDisplay, d)
+	}
+
+	return c, nil
+}
+
+// Broadcast sends the "raw" command to every instance, concurrently.
+// It returns the errors of the instances where the command failed, by display. The map is empty if none did.
+func (r *Registry) Broadcast(ctx context.Context, rawCmd string) map[Display]error {
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		errs = make(map[Display]error)
+	)
+
+	for _, d := range r.displays {
+		wg.Add(1)
+
+		go func(d Display) {
+			defer wg.Done()
+
+			if err := r.clients[d].QueryContext(ctx, rawCmd, nil); err != nil {
+				mu.Lock()
+				errs[d] = err
+				mu.Unlock()
+			}
+		}(d)
+	}
+
+	wg.Wait()
+
+	return errs
+}
+
+// SubscribeEvents subscribes to the events of every instance, and merges them into a single channel.
+// Each event has its Display set to the name of the display it came from, and errors are wrapped with it.
+// The channels are closed once every subscription has ended, or the context is done.
+func (r *Registry) SubscribeEvents(ctx context.Context, event EventType, moreEvents ...EventType) (chan Event, chan error, error) {
+	// Cancelling this context tears down the subscriptions already made, if a later one fails.
+	ctx, cancel := context.WithCancel(ctx)
+
+	var (
+		eventsChannel = make(chan Event)
+		errorsChannel = make(chan error)
+		wg            sync.WaitGroup
+	)
+
+	for _, d := range r.displays {
+		eventCh, errCh, err := r.clients[d].SubscribeEventsContext(ctx, event, moreEvents...)
+		if err != nil {
+			cancel()
+			return nil, nil, fmt.Errorf("display %s: %w", d, err)
+		}
+
+		wg.Add(1)
+
+		go func(d Display, eventCh chan Event, errCh chan error) {
+			defer wg.Done()
+
+			// A nil channel blocks forever, which takes it out of the select once it's closed.
+			for eventCh != nil || errCh != nil {
+				select {
+				case ev, ok := <-eventCh:
+					if !ok {
+						eventCh = nil
+						continue
+					}
+
+					ev.Display = d.String()
+
+					select {
+					case eventsChannel <- ev:
+					case <-ctx.Done():
+						return
+					}
+				case err, ok := <-errCh:
+					if !ok {
+						errCh = nil
+						continue
+					}
+
+					select {
+					case errorsChannel <- fmt.Errorf("display %s: %w", d, err):
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}(d, eventCh, errCh)
+	}
+
+	go func() {
+		wg.Wait()
+		cancel()
+		close(eventsChannel)
+		close(errorsChannel)
+	}()
+
+	return eventsChannel, errorsChannel, nil
+}
@@ -0,0 +1,79 @@
+package bspc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diogox/bspc-go"
+	"github.com/diogox/bspc-go/bspctest"
+)
+
+func TestRegistry(t *testing.T) {
+	newRegistry := func(t *testing.T) (*bspc.Registry, *bspctest.Server, *bspctest.Server) {
+		srv0, srv1 := bspctest.NewServer(t), bspctest.NewServer(t)
+
+		r, err := bspc.NewRegistryWithSockets([]bspc.Socket{
+			{Path: srv0.SocketPath(), Display: bspc.Display{Number: 0}},
+			{Path: srv1.SocketPath(), Display: bspc.Display{Number: 1}},
+		}, nil)
+		require.NoError(t, err)
+
+		return r, srv0, srv1
+	}
+
+	t.Run("should return the client for a display", func(t *testing.T) {
+		r, _, srv1 := newRegistry(t)
+
+		c, err := r.Client(":1")
+		require.NoError(t, err)
+		require.NoError(t, c.Desktop(bspc.Focused()).Layout(bspc.LayoutTypeMonocle))
+
+		assert.Equal(t, []string{"desktop focused --layout monocle"}, srv1.Commands())
+
+		_, err = r.Client(":2")
+		assert.True(t, errors.Is(err, bspc.ErrUnknownDisplay))
+	})
+
+	t.Run("should broadcast commands to every instance", func(t *testing.T) {
+		r, srv0, srv1 := newRegistry(t)
+		srv1.Fail("wm --restart", "")
+
+		errs := r.Broadcast(context.Background(), "wm --restart")
+
+		assert.Equal(t, []string{"wm --restart"}, srv0.Commands())
+		assert.Equal(t, []string{"wm --restart"}, srv1.Commands())
+		assert.Len(t, errs, 1)
+		assert.Error(t, errs[bspc.Display{Number: 1}])
+	})
+
+	t.Run("should merge event streams, tagged with their display", func(t *testing.T) {
+		r, srv0, srv1 := newRegistry(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		eventCh, _, err := r.SubscribeEvents(ctx, bspc.EventTypeMonitorFocus)
+		require.NoError(t, err)
+
+		srv0.WaitForSubscribers(1)
+		srv1.WaitForSubscribers(1)
+
+		srv1.Publish("monitor_focus 0x00200002")
+		ev := <-eventCh
+		assert.Equal(t, ":1.0", ev.Display)
+		assert.Equal(t, bspc.EventMonitorFocus{MonitorID: 0x00200002}, ev.Payload)
+
+		srv0.Publish("monitor_focus 0x00200003")
+		ev = <-eventCh
+		assert.Equal(t, ":0.0", ev.Display)
+		assert.Equal(t, bspc.EventMonitorFocus{MonitorID: 0x00200003}, ev.Payload)
+
+		cancel()
+		_, ok := <-eventCh
+		assert.False(t, ok)
+	})
+}
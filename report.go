@@ -0,0 +1,182 @@
+package bspc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// reportPrefix is the default value of bspwm's `status_prefix` setting, that every report starts with.
+const reportPrefix = "W"
+
+type (
+	// ReportStatus holds the info in a "report" event, as used by status bars.
+	ReportStatus struct {
+		Monitors []ReportMonitor
+	}
+
+	// ReportMonitor holds the status of a monitor, and of its desktops.
+	ReportMonitor struct {
+		Name     string
+		Focused  bool
+		Desktops []ReportDesktop
+
+		// Layout is the layout of the monitor's focused desktop.
+		Layout LayoutType
+
+		// FocusedNodeState is the state of the focused node of the monitor's focused desktop.
+		// It is nil if that node isn't a window, or if bspwm didn't report it.
+		FocusedNodeState *StateType
+
+		// FocusedNodeFlags are the active flags of the focused node of the monitor's focused desktop.
+		FocusedNodeFlags []FlagType
+	}
+
+	// ReportDesktop holds the status of a desktop.
+	ReportDesktop struct {
+		Name     string
+		Focused  bool
+		Occupied bool
+		Urgent   bool
+	}
+)
+
+// FocusedMonitor returns the focused monitor, if it was reported.
+func (rs ReportStatus) FocusedMonitor() (ReportMonitor, bool) {
+	for _, m := range rs.Monitors {
+		if m.Focused {
+			return m, true
+		}
+	}
+
+	return ReportMonitor{}, false
+}
+
+// isReport returns true if the raw event is a report, which is the only event that doesn't start with its name.
+func isReport(rawEvent string) bool {
+	return strings.HasPrefix(rawEvent, reportPrefix)
+}
+
+// ParseReport parses a raw report, in bspwm's format: W<item>:<item>:...
+// It only supports the default `status_prefix`.
+func ParseReport(report string) (ReportStatus, error) {
+	if !isReport(report) {
+		return ReportStatus{}, fmt.Errorf("report must start with '%s'", reportPrefix)
+	}
+
+	var (
+		status  ReportStatus
+		monitor *ReportMonitor
+	)
+
+	for _, item := range strings.Split(strings.TrimPrefix(strings.TrimSpace(report), reportPrefix), ":") {
+		if item == "" {
+			return ReportStatus{}, errors.New("empty report item")
+		}
+
+		kind, value := item[0], item[1:]
+
+		if kind == 'M' || kind == 'm' {
+			status.Monitors = append(status.Monitors, ReportMonitor{
+				Name:    value,
+				Focused: kind == 'M',
+			})
+			monitor = &status.Monitors[len(status.Monitors)-1]
+
+			continue
+		}
+
+		if monitor == nil {
+			return ReportStatus{}, fmt.Errorf("report item '%s' doesn't belong to a monitor", item)
+		}
+
+		switch kind {
+		case 'O', 'o', 'F', 'f', 'U', 'u':
+			monitor.Desktops = append(monitor.Desktops, ReportDesktop{
+				Name:     value,
+				Focused:  kind == 'O' || kind == 'F' || kind == 'U',
+				Occupied: kind != 'F' && kind != 'f',
+				Urgent:   kind == 'U' || kind == 'u',
+			})
+		case 'L':
+			layout, err := reportLayout(value)
+			if err != nil {
+				return ReportStatus{}, err
+			}
+
+			monitor.Layout = layout
+		case 'T':
+			state, err := reportState(value)
+			if err != nil {
+				return ReportStatus{}, err
+			}
+
+			monitor.FocusedNodeState = state
+		case 'G':
+			flags, err := reportFlags(value)
+			if err != nil {
+				return ReportStatus{}, err
+			}
+
+			monitor.FocusedNodeFlags = flags
+		default:
+			return ReportStatus{}, fmt.Errorf("unknown report item '%s'", item)
+		}
+	}
+
+	return status, nil
+}
+
+func reportLayout(value string) (LayoutType, error) {
+	switch value {
+	case "T":
+		return LayoutTypeTiled, nil
+	case "M":
+		return LayoutTypeMonocle, nil
+	default:
+		return "", fmt.Errorf("invalid report layout '%s'", value)
+	}
+}
+
+func reportState(value string) (*StateType, error) {
+	var state StateType
+
+	switch value {
+	case "T":
+		state = StateTypeTiled
+	case "P":
+		state = StateTypePseudoTiled
+	case "F":
+		state = StateTypeFloating
+	case "=":
+		state = StateTypeFullscreen
+	case "@":
+		// The focused node isn't a window.
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("invalid report state '%s'", value)
+	}
+
+	return &state, nil
+}
+
+func reportFlags(value string) ([]FlagType, error) {
+	flags := make([]FlagType, 0, len(value))
+
+	for _, f := range value {
+		switch f {
+		case 'S':
+			flags = append(flags, FlagTypeSticky)
+		case 'P':
+			flags = append(flags, FlagTypePrivate)
+		case 'L':
+			flags = append(flags, FlagTypeLocked)
+		case 'M':
+			flags = append(flags, FlagTypeMarked)
+		default:
+			return nil, fmt.Errorf("invalid report flag '%c'", f)
+		}
+	}
+
+	return flags, nil
+}
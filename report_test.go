@@ -0,0 +1,63 @@
+package bspc_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diogox/bspc-go"
+)
+
+func TestParseReport(t *testing.T) {
+	t.Run("should parse monitors, desktops and the focused node's status", func(t *testing.T) {
+		status, err := bspc.ParseReport("WMeDP-1:OI:fII:uIII:LT:TF:GSL:mHDMI-1:Fweb:LM:T@:G\n")
+		require.NoError(t, err)
+
+		floating := bspc.StateTypeFloating
+
+		assert.Equal(t, bspc.ReportStatus{
+			Monitors: []bspc.ReportMonitor{
+				{
+					Name:    "eDP-1",
+					Focused: true,
+					Desktops: []bspc.ReportDesktop{
+						{Name: "I", Focused: true, Occupied: true},
+						{Name: "II"},
+						{Name: "III", Occupied: true, Urgent: true},
+					},
+					Layout:           bspc.LayoutTypeTiled,
+					FocusedNodeState: &floating,
+					FocusedNodeFlags: []bspc.FlagType{bspc.FlagTypeSticky, bspc.FlagTypeLocked},
+				},
+				{
+					Name: "HDMI-1",
+					Desktops: []bspc.ReportDesktop{
+						{Name: "web", Focused: true},
+					},
+					Layout:           bspc.LayoutTypeMonocle,
+					FocusedNodeFlags: []bspc.FlagType{},
+				},
+			},
+		}, status)
+
+		focused, ok := status.FocusedMonitor()
+		assert.True(t, ok)
+		assert.Equal(t, "eDP-1", focused.Name)
+	})
+
+	t.Run("should reject malformed reports", func(t *testing.T) {
+		for _, r := range []string{
+			"MeDP-1:OI",
+			"WOI:MeDP-1",
+			"WMeDP-1:LX",
+			"WMeDP-1:TX",
+			"WMeDP-1:GX",
+			"WMeDP-1::OI",
+			"WMeDP-1:XI",
+		} {
+			_, err := bspc.ParseReport(r)
+			assert.Error(t, err, r)
+		}
+	})
+}
@@ -0,0 +1,378 @@
+package bspc
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// rulePatternWildcard matches any class, instance or name, in a rule's pattern.
+	rulePatternWildcard = "*"
+
+	ruleSeparator        = " => "
+	oneShotRuleSeparator = " -> "
+)
+
+type (
+	// Rule is applied by bspwm to the windows matching its pattern, when they're managed.
+	// An empty Class, Instance or Name matches any value.
+	Rule struct {
+		Class    string
+		Instance string
+		Name     string
+		// OneShot rules are removed as soon as they're applied to a window.
+		OneShot      bool
+		Consequences RuleConsequences
+	}
+
+	// RuleConsequences are what a rule does to the windows it matches.
+	// The zero value of each field leaves the corresponding consequence unset.
+	RuleConsequences struct {
+		Monitor    Selector
+		Desktop    Selector
+		Node       Selector
+		State      StateType
+		Layer      LayerType
+		SplitDir   DirectionType
+		SplitRatio float64
+		Rectangle  *Rectangle
+		Hidden     ToggleType
+		Sticky     ToggleType
+		Private    ToggleType
+		Locked     ToggleType
+		Marked     ToggleType
+		Center     ToggleType
+		Follow     ToggleType
+		Manage     ToggleType
+		Focus      ToggleType
+		// Extra holds any other consequence, such as honor_size_hints, as key=value pairs.
+		Extra []string
+	}
+
+	// RuleSelector selects the rules to remove. See RulePattern, RuleIndex, RuleHead and RuleTail.
+	RuleSelector string
+
+	// RuleCommand builds and sends `rule` commands.
+	// Each method sends a single command to bspwm, and returns a *CommandError if bspwm rejects it.
+	// Arguments are validated before being sent, in which case the returned error matches ErrInvalidArgument.
+	RuleCommand struct {
+		client client
+		ctx    context.Context
+	}
+)
+
+const (
+	// RuleHead selects the first rule.
+	RuleHead RuleSelector = "head"
+	// RuleTail selects the last rule.
+	RuleTail RuleSelector = "tail"
+)
+
+// RulePattern selects the rules with the given pattern. Empty values match any rule.
+func RulePattern(class, instance, name string) RuleSelector {
+	return RuleSelector(formatRulePattern(class, instance, name))
+}
+
+// RuleIndex selects the rule at the given position, starting from zero, as listed by RuleCommand.List.
+// bspwm counts rules from one, and only reads indexes prefixed with ^, which this takes care of.
+func RuleIndex(i int) RuleSelector {
+	return RuleSelector("^" + strconv.Itoa(i+1))
+}
+
+// Rules returns a builder for the `rule` commands.
+func (c client) Rules() RuleCommand {
+	return RuleCommand{
+		client: c,
+		ctx:    context.Background(),
+	}
+}
+
+// WithContext returns a copy of the builder, that sends its commands under the given context.
+func (rc RuleCommand) WithContext(ctx context.Context) RuleCommand {
+	rc.ctx = ctx
+	return rc
+}
+
+// Add adds the rule, after the existing ones.
+func (rc RuleCommand) Add(r Rule) error {
+	if err := r.Consequences.validate(); err != nil {
+		return err
+	}
+
+	cmd := ipcCommand{"rule", "--add", formatRulePattern(r.Class, r.Instance, r.Name)}
+	if r.OneShot {
+		cmd = append(cmd, "--one-shot")
+	}
+
+	return rc.client.query(rc.ctx, append(cmd, r.Consequences.args()...), nil)
+}
+
+// Remove removes the rules matching any of the selectors.
+func (rc RuleCommand) Remove(sel RuleSelector, moreSels ...RuleSelector) error {
+	cmd := ipcCommand{"rule", "--remove"}
+	for _, s := range append([]RuleSelector{sel}, moreSels...) {
+		if s == "" {
+			return fmt.Errorf("%w: empty rule selector", ErrInvalidArgument)
+		}
+
+		cmd = append(cmd, string(s))
+	}
+
+	return rc.client.query(rc.ctx, cmd, nil)
+}
+
+// List returns the rules, in the order bspwm applies them.
+func (rc RuleCommand) List() ([]Rule, error) {
+	var rules []Rule
+
+	err := rc.client.query(rc.ctx, ipcCommand{"rule", "--list"}, func(payload []byte) error {
+		for _, l := range strings.Split(string(payload), "\n") {
+			if l == "" {
+				continue
+			}
+
+			r, err := parseRule(l)
+			if err != nil {
+				return err
+			}
+
+			rules = append(rules, r)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// String returns the consequences the way bspwm lists them: space separated key=value pairs.
+func (rc RuleConsequences) String() string {
+	return strings.Join(rc.args(), " ")
+}
+
+// ParseRuleConsequences parses consequences in the format bspwm lists them, and passes them to the external
+// rules command in: space separated key=value pairs. Empty values are left unset.
+func ParseRuleConsequences(s string) (RuleConsequences, error) {
+	var rc RuleConsequences
+
+	for _, field := range strings.Fields(s) {
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) != 2 {
+			return RuleConsequences{}, fmt.Errorf("%w: invalid rule consequence %s", ErrInvalidArgument, field)
+		}
+
+		key, value := parts[0], parts[1]
+
+		// bspwm prints every consequence to the external rules command, leaving the unset ones empty.
+		if value == "" {
+			continue
+		}
+
+		var err error
+		switch key {
+		case "monitor":
+			rc.Monitor, err = ParseSelector(value)
+		case "desktop":
+			rc.Desktop, err = ParseSelector(value)
+		case "node":
+			rc.Node, err = ParseSelector(value)
+		case "state":
+			rc.State = StateType(value)
+		case "layer":
+			rc.Layer = LayerType(value)
+		case "split_dir":
+			rc.SplitDir = DirectionType(value)
+		case "split_ratio":
+			rc.SplitRatio, err = strconv.ParseFloat(value, 64)
+		case "rectangle":
+			var r Rectangle
+			r, err = ParseRectangle(value)
+			rc.Rectangle = &r
+		default:
+			toggle := rc.toggle(key)
+			if toggle == nil {
+				rc.Extra = append(rc.Extra, field)
+				continue
+			}
+
+			*toggle = parseToggle(value)
+		}
+
+		if err != nil {
+			return RuleConsequences{}, fmt.Errorf("%w: invalid rule consequence %s: %v", ErrInvalidArgument, field, err)
+		}
+	}
+
+	if err := rc.validate(); err != nil {
+		return RuleConsequences{}, err
+	}
+
+	return rc, nil
+}
+
+func (rc RuleConsequences) validate() error {
+	if rc.State != "" && !rc.State.IsValid() {
+		return fmt.Errorf("%w: invalid state %s", ErrInvalidArgument, rc.State)
+	}
+
+	if rc.Layer != "" && !rc.Layer.IsValid() {
+		return fmt.Errorf("%w: invalid layer %s", ErrInvalidArgument, rc.Layer)
+	}
+
+	if rc.SplitDir != "" && !rc.SplitDir.IsValid() {
+		return fmt.Errorf("%w: invalid direction %s", ErrInvalidArgument, rc.SplitDir)
+	}
+
+	if rc.SplitRatio != 0 && !isValidRatio(rc.SplitRatio) {
+		return fmt.Errorf("%w: invalid ratio %v", ErrInvalidArgument, rc.SplitRatio)
+	}
+
+	for _, key := range ruleToggleKeys {
+		if toggle := *rc.toggle(key); toggle != "" && !toggle.IsValid() {
+			return fmt.Errorf("%w: invalid %s value %s", ErrInvalidArgument, key, toggle)
+		}
+	}
+
+	return nil
+}
+
+// args returns the consequences as the key=value arguments of `rule --add`.
+func (rc RuleConsequences) args() []string {
+	var args []string
+
+	add := func(key, value string) {
+		if value != "" {
+			args = append(args, key+"="+value)
+		}
+	}
+
+	if !rc.Monitor.IsZero() {
+		add("monitor", rc.Monitor.String())
+	}
+
+	if !rc.Desktop.IsZero() {
+		add("desktop", rc.Desktop.String())
+	}
+
+	if !rc.Node.IsZero() {
+		add("node", rc.Node.String())
+	}
+
+	add("state", string(rc.State))
+	add("layer", string(rc.Layer))
+	add("split_dir", string(rc.SplitDir))
+
+	if rc.SplitRatio != 0 {
+		add("split_ratio", formatFloat(rc.SplitRatio))
+	}
+
+	if rc.Rectangle != nil {
+		add("rectangle", rc.Rectangle.String())
+	}
+
+	for _, key := range ruleToggleKeys {
+		add(key, string(*rc.toggle(key)))
+	}
+
+	return append(args, rc.Extra...)
+}
+
+// ruleToggleKeys are the keys of the on/off consequences, in the order they're sent.
+var ruleToggleKeys = []string{"hidden", "sticky", "private", "locked", "marked", "center", "follow", "manage", "focus"}
+
+// toggle returns the field holding the on/off consequence with the given key, or nil if there's none.
+func (rc *RuleConsequences) toggle(key string) *ToggleType {
+	switch key {
+	case "hidden":
+		return &rc.Hidden
+	case "sticky":
+		return &rc.Sticky
+	case "private":
+		return &rc.Private
+	case "locked":
+		return &rc.Locked
+	case "marked":
+		return &rc.Marked
+	case "center":
+		return &rc.Center
+	case "follow":
+		return &rc.Follow
+	case "manage":
+		return &rc.Manage
+	case "focus":
+		return &rc.Focus
+	default:
+		return nil
+	}
+}
+
+// parseToggle accepts the same boolean values bspwm does.
+func parseToggle(value string) ToggleType {
+	switch value {
+	case "true":
+		return ToggleTypeOn
+	case "false":
+		return ToggleTypeOff
+	default:
+		return ToggleType(value)
+	}
+}
+
+// parseRule parses a line of `rule --list`, as in: class:instance:name => consequences
+// One-shot rules use "->" instead of "=>".
+func parseRule(line string) (Rule, error) {
+	var r Rule
+
+	// The separator's trailing space is gone when the rule has no consequences.
+	line += " "
+
+	sep := strings.Index(line, ruleSeparator)
+	if oneShot := strings.Index(line, oneShotRuleSeparator); oneShot != -1 && (sep == -1 || oneShot < sep) {
+		sep = oneShot
+		r.OneShot = true
+	}
+
+	if sep == -1 {
+		return Rule{}, fmt.Errorf("invalid rule: %s", strings.TrimSpace(line))
+	}
+
+	pattern := strings.Split(line[:sep], ":")
+	if len(pattern) != 3 {
+		return Rule{}, fmt.Errorf("invalid rule pattern: %s", line[:sep])
+	}
+
+	r.Class, r.Instance, r.Name = parseRulePatternField(pattern[0]), parseRulePatternField(pattern[1]), parseRulePatternField(pattern[2])
+
+	consequences, err := ParseRuleConsequences(line[sep+len(ruleSeparator):])
+	if err != nil {
+		return Rule{}, fmt.Errorf("invalid rule %s: %w", strings.TrimSpace(line), err)
+	}
+
+	r.Consequences = consequences
+
+	return r, nil
+}
+
+func formatRulePattern(class, instance, name string) string {
+	fields := []string{class, instance, name}
+	for i, f := range fields {
+		if f == "" {
+			fields[i] = rulePatternWildcard
+		}
+	}
+
+	return strings.Join(fields, ":")
+}
+
+func parseRulePatternField(f string) string {
+	if f == rulePatternWildcard {
+		return ""
+	}
+
+	return f
+}
@@ -0,0 +1,91 @@
+package bspc_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diogox/bspc-go"
+)
+
+func TestRuleCommand(t *testing.T) {
+	t.Run("should add and remove rules", func(t *testing.T) {
+		c, srv := newTestClient(t)
+
+		require.NoError(t, c.Rules().Add(bspc.Rule{
+			Class:   "firefox",
+			OneShot: true,
+			Consequences: bspc.RuleConsequences{
+				Desktop: bspc.Index(2),
+				State:   bspc.StateTypeFloating,
+				Follow:  bspc.ToggleTypeOn,
+			},
+		}))
+		require.NoError(t, c.Rules().Remove(bspc.RulePattern("firefox", "", ""), bspc.RuleHead, bspc.RuleIndex(3)))
+
+		assert.Equal(t, []string{
+			"rule --add firefox:*:* --one-shot desktop=^2 state=floating follow=on",
+			"rule --remove firefox:*:* head ^4",
+		}, srv.Commands())
+	})
+
+	t.Run("should not add rules with invalid consequences", func(t *testing.T) {
+		c, srv := newTestClient(t)
+
+		err := c.Rules().Add(bspc.Rule{Class: "mpv", Consequences: bspc.RuleConsequences{Sticky: "maybe"}})
+
+		assert.True(t, errors.Is(err, bspc.ErrInvalidArgument))
+		assert.Empty(t, srv.Commands())
+	})
+
+	t.Run("should list the rules", func(t *testing.T) {
+		c, srv := newTestClient(t)
+		srv.Respond("rule --list", "Gimp:*:* => state=floating follow=on\n"+
+			"*:scratchpad:* -> sticky=true rectangle=800x600+10+20 honor_size_hints=on\n"+
+			"Zathura:*:* => \n")
+
+		rules, err := c.Rules().List()
+		require.NoError(t, err)
+
+		assert.Equal(t, []bspc.Rule{
+			{
+				Class:        "Gimp",
+				Consequences: bspc.RuleConsequences{State: bspc.StateTypeFloating, Follow: bspc.ToggleTypeOn},
+			},
+			{
+				Instance: "scratchpad",
+				OneShot:  true,
+				Consequences: bspc.RuleConsequences{
+					Rectangle: &bspc.Rectangle{X: 10, Y: 20, Width: 800, Height: 600},
+					Sticky:    bspc.ToggleTypeOn,
+					Extra:     []string{"honor_size_hints=on"},
+				},
+			},
+			{Class: "Zathura"},
+		}, rules)
+		assert.Equal(t, "rectangle=800x600+10+20 sticky=on honor_size_hints=on", rules[1].Consequences.String())
+	})
+}
+
+func TestParseRuleConsequences(t *testing.T) {
+	t.Run("should parse what String returns", func(t *testing.T) {
+		const s = "monitor=primary desktop=^2 node=@^2:/ layer=above split_dir=east split_ratio=0.3 hidden=off manage=on"
+
+		rc, err := bspc.ParseRuleConsequences(s)
+		require.NoError(t, err)
+
+		assert.Equal(t, bspc.LayerTypeAbove, rc.Layer)
+		assert.Equal(t, bspc.DirectionTypeRight, rc.SplitDir)
+		assert.Equal(t, 0.3, rc.SplitRatio)
+		assert.Equal(t, s, rc.String())
+	})
+
+	t.Run("should fail on invalid consequences", func(t *testing.T) {
+		for _, s := range []string{"state", "state=sideways", "split_ratio=2", "focus=yes", "desktop=#"} {
+			_, err := bspc.ParseRuleConsequences(s)
+			assert.True(t, errors.Is(err, bspc.ErrInvalidArgument), s)
+		}
+	})
+}
@@ -0,0 +1,390 @@
+// Package scratchpad toggles windows in and out of view, on whichever desktop is focused.
+//
+// A scratchpad is a floating window that's hidden with bspwm's hidden flag, instead of being closed.
+// Showing it brings it to the focused desktop, centered and focused. If it's gone, it's launched again,
+// and claimed as soon as bspwm manages it.
+// Example usage:
+//
+//	m := scratchpad.NewManager(c, nil)
+//	err := m.Toggle(ctx, scratchpad.Scratchpad{
+//		Instance:  "dropdown",
+//		Command:   []string{"alacritty", "--class", "dropdown"},
+//		Rectangle: bspc.Rectangle{Width: 1200, Height: 600},
+//	})
+package scratchpad
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"time"
+
+	"github.com/diogox/bspc-go"
+)
+
+const (
+	// launchTimeout is how long a launched scratchpad has to show up.
+	launchTimeout = 10 * time.Second
+	// cleanupTimeout is how long removing the launch rule can take, once a launch fails.
+	cleanupTimeout = 2 * time.Second
+)
+
+var (
+	// ErrNoCommand is returned when a scratchpad that's gone has no command to launch it again.
+	ErrNoCommand = errors.New("scratchpad has no command")
+	// ErrNotLaunched is returned when a launched scratchpad doesn't show up in time.
+	ErrNotLaunched = errors.New("scratchpad did not show up")
+)
+
+type (
+	// Scratchpad is a window, found by its class and instance, and the command that launches it.
+	Scratchpad struct {
+		// Class and Instance match the window's WM_CLASS. An empty value matches any, but not both.
+		Class    string
+		Instance string
+		// Command launches the window, when it's gone.
+		Command []string
+		// Rectangle is the window's floating geometry, relative to the focused monitor. If its position is
+		// zero, the window is centered. If its size is zero, the window keeps its own.
+		Rectangle bspc.Rectangle
+		// Sticky scratchpads follow the focus across desktops, while they're shown.
+		Sticky bool
+	}
+
+	// Launcher starts a scratchpad's command, without waiting for it.
+	Launcher func(command []string) error
+
+	// Manager shows and hides scratchpads.
+	Manager struct {
+		client bspc.Client
+		launch Launcher
+	}
+
+	// window is a scratchpad's window, and where it sits.
+	window struct {
+		node    *bspc.Node
+		monitor *bspc.Monitor
+		desktop *bspc.Desktop
+	}
+)
+
+// Matches returns true if the client is the scratchpad's window.
+func (s Scratchpad) Matches(c *bspc.NodeClient) bool {
+	if c == nil || (s.Class == "" && s.Instance == "") {
+		return false
+	}
+
+	return (s.Class == "" || c.ClassName == s.Class) && (s.Instance == "" || c.InstanceName == s.Instance)
+}
+
+// NewManager returns a manager for the bspwm instance behind the client.
+// If the value passed in as a launcher is nil, commands are run as detached processes.
+func NewManager(c bspc.Client, launch Launcher) *Manager {
+	if launch == nil {
+		launch = startProcess
+	}
+
+	return &Manager{client: c, launch: launch}
+}
+
+// Toggle hides the scratchpad if it's shown on the focused desktop, and shows it otherwise.
+func (m *Manager) Toggle(ctx context.Context, s Scratchpad) error {
+	st, w, err := m.find(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	if w != nil && !w.node.Hidden && w.desktop.ID == focusedDesktopID(st) {
+		return m.node(ctx, w.node.ID).Flag(bspc.FlagTypeHidden, true)
+	}
+
+	return m.show(ctx, s, st, w)
+}
+
+// Show shows the scratchpad on the focused desktop, centered and focused, launching it if it's gone.
+func (m *Manager) Show(ctx context.Context, s Scratchpad) error {
+	st, w, err := m.find(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	return m.show(ctx, s, st, w)
+}
+
+// Hide hides the scratchpad, if it's there.
+func (m *Manager) Hide(ctx context.Context, s Scratchpad) error {
+	_, w, err := m.find(ctx, s)
+	if err != nil || w == nil || w.node.Hidden {
+		return err
+	}
+
+	return m.node(ctx, w.node.ID).Flag(bspc.FlagTypeHidden, true)
+}
+
+func (m *Manager) show(ctx context.Context, s Scratchpad, st bspc.State, w *window) error {
+	if w == nil {
+		id, err := m.launchAndClaim(ctx, s)
+		if err != nil {
+			return err
+		}
+
+		if st, err = m.dumpState(ctx); err != nil {
+			return err
+		}
+
+		if w = locate(st, id); w == nil {
+			return fmt.Errorf("%w: window 0x%08X went away", ErrNotLaunched, uint(id))
+		}
+	}
+
+	nc := m.node(ctx, w.node.ID)
+	monitor := st.FindMonitor(st.FocusedMonitorID)
+	if monitor == nil {
+		return fmt.Errorf("focused monitor 0x%08X not found", uint(st.FocusedMonitorID))
+	}
+
+	current := w.node.Client.FloatingRectangle
+	if w.desktop.ID != monitor.FocusedDesktopID {
+		// bspwm doesn't move sticky windows between desktops.
+		if w.node.Sticky {
+			if err := nc.Flag(bspc.FlagTypeSticky, false); err != nil {
+				return err
+			}
+		}
+
+		if err := nc.ToDesktop(bspc.ByID(monitor.FocusedDesktopID), false); err != nil {
+			return err
+		}
+
+		// bspwm fits floating windows into the monitor they're sent to.
+		if w.monitor.ID != monitor.ID {
+			n, err := m.client.TreeContext(ctx, bspc.ByID(w.node.ID))
+			if err != nil {
+				return fmt.Errorf("failed to query scratchpad: %w", err)
+			}
+
+			current = n.Client.FloatingRectangle
+		}
+	}
+
+	if w.node.Client.State != bspc.StateTypeFloating {
+		if err := nc.State(bspc.StateTypeFloating); err != nil {
+			return err
+		}
+	}
+
+	target := s.geometry(monitor.Rectangle, current)
+	if dw, dh := target.Width-current.Width, target.Height-current.Height; dw != 0 || dh != 0 {
+		if err := nc.Resize(bspc.HandleTypeBottomRight, dw, dh); err != nil {
+			return err
+		}
+	}
+
+	if dx, dy := target.X-current.X, target.Y-current.Y; dx != 0 || dy != 0 {
+		if err := nc.Move(dx, dy); err != nil {
+			return err
+		}
+	}
+
+	if s.Sticky {
+		if err := nc.Flag(bspc.FlagTypeSticky, true); err != nil {
+			return err
+		}
+	}
+
+	if w.node.Hidden {
+		if err := nc.Flag(bspc.FlagTypeHidden, false); err != nil {
+			return err
+		}
+	}
+
+	return nc.Focus()
+}
+
+// launchAndClaim launches the scratchpad, and returns the ID of its window once bspwm manages it.
+// A one-shot rule makes the window float, hidden, so that it doesn't show up tiled before being placed.
+func (m *Manager) launchAndClaim(ctx context.Context, s Scratchpad) (bspc.ID, error) {
+	if len(s.Command) == 0 {
+		return bspc.NilID, ErrNoCommand
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, launchTimeout)
+	defer cancel()
+
+	// The subscription starts before the launch, so that the window can't be missed.
+	eventCh, errCh, err := m.client.SubscribeEventsContext(ctx, bspc.EventTypeNodeAdd)
+	if err != nil {
+		return bspc.NilID, fmt.Errorf("failed to subscribe to events: %w", err)
+	}
+
+	rule := bspc.Rule{
+		Class:    s.Class,
+		Instance: s.Instance,
+		OneShot:  true,
+		Consequences: bspc.RuleConsequences{
+			State:  bspc.StateTypeFloating,
+			Hidden: bspc.ToggleTypeOn,
+		},
+	}
+
+	if err := m.client.Rules().WithContext(ctx).Add(rule); err != nil {
+		return bspc.NilID, err
+	}
+
+	if err := m.launch(s.Command); err != nil {
+		return bspc.NilID, m.abandon(rule, fmt.Errorf("failed to launch scratchpad: %w", err))
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return bspc.NilID, m.abandon(rule, fmt.Errorf("%w: %v", ErrNotLaunched, ctx.Err()))
+		case err, ok := <-errCh:
+			if ok {
+				return bspc.NilID, m.abandon(rule, err)
+			}
+
+			errCh = nil
+		case ev, ok := <-eventCh:
+			if !ok {
+				eventCh = nil
+				continue
+			}
+
+			p, ok := ev.Payload.(bspc.EventNodeAdd)
+			if !ok {
+				continue
+			}
+
+			n, err := m.client.TreeContext(ctx, bspc.ByID(p.NodeID))
+			if err != nil {
+				// It may have been closed already.
+				continue
+			}
+
+			if s.Matches(n.Client) {
+				return n.ID, nil
+			}
+		}
+	}
+}
+
+// abandon removes the launch rule of a launch that failed with the given error, and returns that error.
+// The launch may have failed because the caller's context is done, so the rule is removed under a context of its own.
+func (m *Manager) abandon(r bspc.Rule, err error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+
+	if rmErr := m.removeRule(ctx, r); rmErr != nil {
+		return fmt.Errorf("%w (and failed to remove the launch rule: %v)", err, rmErr)
+	}
+
+	return err
+}
+
+// removeRule removes the launch rule, if it wasn't applied yet, as it would otherwise catch the next window.
+// Rules added since can follow it, so it's looked for from the end of the list, and removed by its index.
+func (m *Manager) removeRule(ctx context.Context, r bspc.Rule) error {
+	rc := m.client.Rules().WithContext(ctx)
+
+	rules, err := rc.List()
+	if err != nil {
+		return err
+	}
+
+	for i := len(rules) - 1; i >= 0; i-- {
+		if rules[i].OneShot && rules[i].Class == r.Class && rules[i].Instance == r.Instance {
+			return rc.Remove(bspc.RuleIndex(i))
+		}
+	}
+
+	return nil
+}
+
+// find returns the state, and the scratchpad's window in it, or nil if it's gone.
+func (m *Manager) find(ctx context.Context, s Scratchpad) (bspc.State, *window, error) {
+	st, err := m.dumpState(ctx)
+	if err != nil {
+		return bspc.State{}, nil, err
+	}
+
+	for i := range st.Monitors {
+		mon := &st.Monitors[i]
+		for j := range mon.Desktops {
+			d := &mon.Desktops[j]
+			if d.Root.ID == bspc.NilID {
+				continue
+			}
+
+			n := d.Root.Find(func(n *bspc.Node) bool {
+				return s.Matches(n.Client)
+			})
+
+			if n != nil {
+				return st, &window{node: n, monitor: mon, desktop: d}, nil
+			}
+		}
+	}
+
+	return st, nil, nil
+}
+
+func (m *Manager) dumpState(ctx context.Context) (bspc.State, error) {
+	st, err := m.client.DumpStateContext(ctx)
+	if err != nil {
+		return bspc.State{}, fmt.Errorf("failed to dump state: %w", err)
+	}
+
+	return st, nil
+}
+
+func (m *Manager) node(ctx context.Context, id bspc.ID) bspc.NodeCommand {
+	return m.client.Node(bspc.ByID(id)).WithContext(ctx)
+}
+
+// geometry returns where the scratchpad goes on the monitor, given its current geometry.
+func (s Scratchpad) geometry(monitor, current bspc.Rectangle) bspc.Rectangle {
+	target := current
+	if s.Rectangle.Width > 0 && s.Rectangle.Height > 0 {
+		target.Width, target.Height = s.Rectangle.Width, s.Rectangle.Height
+	}
+
+	if s.Rectangle.X != 0 || s.Rectangle.Y != 0 {
+		target.X, target.Y = monitor.X+s.Rectangle.X, monitor.Y+s.Rectangle.Y
+		return target
+	}
+
+	center := monitor.Center()
+	target.X, target.Y = center.X-target.Width/2, center.Y-target.Height/2
+
+	return target
+}
+
+// locate returns the window with the given ID, and where it sits.
+func locate(st bspc.State, id bspc.ID) *window {
+	l, ok := st.NodeLocation(id)
+	if !ok {
+		return nil
+	}
+
+	return &window{node: l.Node, monitor: l.Monitor, desktop: l.Desktop}
+}
+
+func focusedDesktopID(st bspc.State) bspc.ID {
+	if m := st.FindMonitor(st.FocusedMonitorID); m != nil {
+		return m.FocusedDesktopID
+	}
+
+	return bspc.NilID
+}
+
+// startProcess runs the command, without waiting for it. It's released, so that it outlives this process.
+func startProcess(command []string) error {
+	cmd := exec.Command(command[0], command[1:]...)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	return cmd.Process.Release()
+}
@@ -0,0 +1,212 @@
+package scratchpad_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diogox/bspc-go"
+	"github.com/diogox/bspc-go/bspctest"
+	"github.com/diogox/bspc-go/scratchpad"
+)
+
+const (
+	monitorID = bspc.ID(0x00200002)
+	desktopI  = bspc.ID(0x00200003)
+	desktopII = bspc.ID(0x00200004)
+	termID    = bspc.ID(0x00E00003)
+	browserID = bspc.ID(0x00E00004)
+)
+
+var dropdown = scratchpad.Scratchpad{
+	Instance:  "dropdown",
+	Command:   []string{"alacritty", "--class", "dropdown"},
+	Rectangle: bspc.Rectangle{Width: 1200, Height: 600},
+}
+
+func window(id bspc.ID, instance string, hidden bool) bspc.Node {
+	return bspc.Node{
+		ID:        id,
+		SplitType: bspc.SplitTypeVertical,
+		Hidden:    hidden,
+		Client: &bspc.NodeClient{
+			ClassName:         "Alacritty",
+			InstanceName:      instance,
+			State:             bspc.StateTypeFloating,
+			LastState:         bspc.StateTypeTiled,
+			Layer:             bspc.LayerTypeNormal,
+			LastLayer:         bspc.LayerTypeNormal,
+			FloatingRectangle: bspc.Rectangle{X: 100, Y: 100, Width: 800, Height: 400},
+		},
+	}
+}
+
+// testState has the desktop I focused, and the window on the desktop II, if any.
+func testState(w *bspc.Node) bspc.State {
+	d2 := bspc.Desktop{ID: desktopII, Name: "II", Layout: bspc.LayoutTypeTiled}
+	if w != nil {
+		d2.Root = *w
+	}
+
+	return bspc.State{
+		FocusedMonitorID: monitorID,
+		Monitors: []bspc.Monitor{{
+			ID:               monitorID,
+			Name:             "eDP-1",
+			FocusedDesktopID: desktopI,
+			Rectangle:        bspc.Rectangle{Width: 1920, Height: 1080},
+			Desktops: []bspc.Desktop{
+				{ID: desktopI, Name: "I", Layout: bspc.LayoutTypeTiled},
+				d2,
+			},
+		}},
+	}
+}
+
+func TestScratchpad_Matches(t *testing.T) {
+	t.Run("should match the class and the instance", func(t *testing.T) {
+		c := &bspc.NodeClient{ClassName: "Alacritty", InstanceName: "dropdown"}
+
+		assert.True(t, scratchpad.Scratchpad{Instance: "dropdown"}.Matches(c))
+		assert.True(t, scratchpad.Scratchpad{Class: "Alacritty", Instance: "dropdown"}.Matches(c))
+		assert.False(t, scratchpad.Scratchpad{Class: "Alacritty", Instance: "Alacritty"}.Matches(c))
+	})
+
+	t.Run("should match nothing without a class nor an instance", func(t *testing.T) {
+		assert.False(t, scratchpad.Scratchpad{}.Matches(&bspc.NodeClient{ClassName: "Alacritty"}))
+	})
+
+	t.Run("should not match receptacles", func(t *testing.T) {
+		assert.False(t, dropdown.Matches(nil))
+	})
+}
+
+func TestManager_Toggle(t *testing.T) {
+	t.Run("should hide the scratchpad, when it's shown on the focused desktop", func(t *testing.T) {
+		c, srv := bspctest.NewClient(t)
+
+		st := testState(nil)
+		st.Monitors[0].Desktops[0].Root = window(termID, "dropdown", false)
+		srv.RespondJSON("wm --dump-state", st)
+
+		require.NoError(t, scratchpad.NewManager(c, nil).Toggle(context.Background(), dropdown))
+
+		assert.Equal(t, []string{
+			"wm --dump-state",
+			"node 0x00E00003 --flag hidden=on",
+		}, srv.Commands())
+	})
+
+	t.Run("should bring the scratchpad to the focused desktop, centered and focused", func(t *testing.T) {
+		c, srv := bspctest.NewClient(t)
+
+		w := window(termID, "dropdown", true)
+		srv.RespondJSON("wm --dump-state", testState(&w))
+
+		require.NoError(t, scratchpad.NewManager(c, nil).Toggle(context.Background(), dropdown))
+
+		assert.Equal(t, []string{
+			"wm --dump-state",
+			"node 0x00E00003 --to-desktop 0x00200003",
+			"node 0x00E00003 --resize bottom_right 400 200",
+			"node 0x00E00003 --move 260 140",
+			"node 0x00E00003 --flag hidden=off",
+			"node 0x00E00003 --focus",
+		}, srv.Commands())
+	})
+
+	t.Run("should launch the scratchpad when it's gone, and claim its window", func(t *testing.T) {
+		c, srv := bspctest.NewClient(t)
+		srv.RespondJSON("wm --dump-state", testState(nil))
+
+		other := window(browserID, "Alacritty", false)
+		srv.RespondJSON("query --tree --node 0x00E00004", other)
+
+		launched := window(termID, "dropdown", true)
+		srv.RespondJSON("query --tree --node 0x00E00003", launched)
+
+		launch := func(command []string) error {
+			assert.Equal(t, dropdown.Command, command)
+
+			st := testState(nil)
+			st.Monitors[0].Desktops[0].Root = launched
+			srv.RespondJSON("wm --dump-state", st)
+
+			srv.WaitForSubscribers(1)
+			srv.Publish(
+				"node_add 0x00200002 0x00200003 0x00000000 0x00E00004",
+				"node_add 0x00200002 0x00200003 0x00000000 0x00E00003",
+			)
+
+			return nil
+		}
+
+		require.NoError(t, scratchpad.NewManager(c, launch).Toggle(context.Background(), dropdown))
+
+		// The subscription has a connection of its own, so it's received along with the next commands.
+		cmds := srv.Commands()
+		for i, cmd := range cmds {
+			if cmd == "subscribe node_add" {
+				cmds = append(cmds[:i], cmds[i+1:]...)
+				break
+			}
+		}
+
+		assert.Equal(t, []string{
+			"wm --dump-state",
+			"rule --add *:dropdown:* --one-shot state=floating hidden=on",
+			"query --tree --node 0x00E00004",
+			"query --tree --node 0x00E00003",
+			"wm --dump-state",
+			"node 0x00E00003 --resize bottom_right 400 200",
+			"node 0x00E00003 --move 260 140",
+			"node 0x00E00003 --flag hidden=off",
+			"node 0x00E00003 --focus",
+		}, cmds)
+	})
+
+	t.Run("should remove the launch rule, when the scratchpad can't be launched", func(t *testing.T) {
+		c, srv := bspctest.NewClient(t)
+		srv.RespondJSON("wm --dump-state", testState(nil))
+		srv.Respond("rule --list", "*:dropdown:* -> state=floating hidden=on\nFirefox:*:* => desktop=^2\n")
+
+		launch := func(command []string) error {
+			return errors.New("executable file not found in $PATH")
+		}
+
+		err := scratchpad.NewManager(c, launch).Toggle(context.Background(), dropdown)
+		assert.Error(t, err)
+
+		// Other rules can be added after the launch rule, so it's removed by its index.
+		assert.Contains(t, srv.Commands(), "rule --remove ^1")
+	})
+
+	t.Run("should remove the launch rule, when the caller gives up on the launch", func(t *testing.T) {
+		c, srv := bspctest.NewClient(t)
+		srv.RespondJSON("wm --dump-state", testState(nil))
+		srv.Respond("rule --list", "*:dropdown:* -> state=floating hidden=on\n")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		launch := func(command []string) error {
+			cancel()
+			return nil
+		}
+
+		err := scratchpad.NewManager(c, launch).Toggle(ctx, dropdown)
+		assert.True(t, errors.Is(err, scratchpad.ErrNotLaunched), err)
+		assert.Contains(t, srv.Commands(), "rule --remove ^1")
+	})
+
+	t.Run("should fail when the scratchpad is gone and has no command", func(t *testing.T) {
+		c, srv := bspctest.NewClient(t)
+		srv.RespondJSON("wm --dump-state", testState(nil))
+
+		err := scratchpad.NewManager(c, nil).Toggle(context.Background(), scratchpad.Scratchpad{Instance: "dropdown"})
+		assert.True(t, errors.Is(err, scratchpad.ErrNoCommand))
+	})
+}
@@ -0,0 +1,261 @@
+package bspc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Selector describes a NODE_SEL, DESKTOP_SEL or MONITOR_SEL, as documented in bspc's manual:
+//
+//	[REFERENCE#]DESCRIPTOR(.[!]MODIFIER)*
+//
+// Selectors are built from one of the descriptor constructors in this file, and refined with
+// With and RelativeTo. The zero value is an empty selector, which bspwm resolves to the focused
+// node, desktop or monitor, depending on the command.
+type Selector struct {
+	reference  *Selector
+	descriptor string
+	modifiers  []Modifier
+}
+
+// Modifier narrows down the nodes, desktops or monitors matched by a selector's descriptor.
+type Modifier string
+
+const (
+	ModifierFocused      Modifier = "focused"
+	ModifierActive       Modifier = "active"
+	ModifierAutomatic    Modifier = "automatic"
+	ModifierLocal        Modifier = "local"
+	ModifierLeaf         Modifier = "leaf"
+	ModifierWindow       Modifier = "window"
+	ModifierSameClass    Modifier = "same_class"
+	ModifierDescendantOf Modifier = "descendant_of"
+	ModifierAncestorOf   Modifier = "ancestor_of"
+	ModifierOccupied     Modifier = "occupied"
+)
+
+// StateModifier matches nodes in the given state.
+func StateModifier(state StateType) Modifier {
+	return Modifier(state)
+}
+
+// FlagModifier matches nodes with the given flag on.
+func FlagModifier(flag FlagType) Modifier {
+	return Modifier(flag)
+}
+
+// LayerModifier matches nodes in the given layer.
+func LayerModifier(layer LayerType) Modifier {
+	return Modifier(layer)
+}
+
+// SplitModifier matches nodes with the given split type.
+func SplitModifier(split SplitType) Modifier {
+	return Modifier(split)
+}
+
+// LayoutModifier matches desktops with the given layout.
+func LayoutModifier(layout LayoutType) Modifier {
+	return Modifier(layout)
+}
+
+// UserLayoutModifier matches desktops with the given user layout.
+func UserLayoutModifier(layout LayoutType) Modifier {
+	return Modifier("user_" + layout)
+}
+
+// Not negates the modifier.
+func Not(m Modifier) Modifier {
+	return "!" + m
+}
+
+// ByID matches the node, desktop or monitor with the given ID.
+func ByID(id ID) Selector {
+	return Selector{descriptor: fmt.Sprintf("0x%08X", uint(id))}
+}
+
+// ByName matches the desktop or monitor with the given name.
+func ByName(name string) Selector {
+	return Selector{descriptor: name}
+}
+
+// Focused matches the focused node, desktop or monitor.
+func Focused() Selector {
+	return Selector{descriptor: "focused"}
+}
+
+// Pointed matches the node or monitor under the pointer.
+func Pointed() Selector {
+	return Selector{descriptor: "pointed"}
+}
+
+// Primary matches the primary monitor.
+func Primary() Selector {
+	return Selector{descriptor: "primary"}
+}
+
+// Direction matches the node or monitor in the given direction, relative to the reference.
+func Direction(dir DirectionType) Selector {
+	return Selector{descriptor: string(dir)}
+}
+
+// Cycle matches the next or previous node, desktop or monitor, relative to the reference.
+func Cycle(dir CycleDirType) Selector {
+	return Selector{descriptor: string(dir)}
+}
+
+// Path matches the node at the given path, e.g. "@/1/2" or "@brother".
+// The leading "@" is added if it is missing.
+func Path(path string) Selector {
+	if !strings.HasPrefix(path, "@") {
+		path = "@" + path
+	}
+
+	return Selector{descriptor: path}
+}
+
+// PathOn matches the node at the given path, in the desktop matching the selector.
+func PathOn(desktopSel Selector, path string) Selector {
+	return Selector{descriptor: "@" + desktopSel.String() + ":" + strings.TrimPrefix(path, "@")}
+}
+
+// Index matches the desktop or monitor at the given position, starting at 1.
+func Index(n int) Selector {
+	return Selector{descriptor: "^" + strconv.Itoa(n)}
+}
+
+// IndexOn matches the desktop at the given position, in the monitor matching the selector.
+func IndexOn(monitorSel Selector, n int) Selector {
+	return Selector{descriptor: monitorSel.String() + ":^" + strconv.Itoa(n)}
+}
+
+// FocusedOn matches the focused desktop of the monitor matching the selector.
+func FocusedOn(monitorSel Selector) Selector {
+	return Selector{descriptor: monitorSel.String() + ":focused"}
+}
+
+// Any matches the first node, desktop or monitor that satisfies the modifiers.
+func Any() Selector {
+	return Selector{descriptor: "any"}
+}
+
+// FirstAncestor matches the first ancestor of the reference that satisfies the modifiers.
+func FirstAncestor() Selector {
+	return Selector{descriptor: "first_ancestor"}
+}
+
+// Last matches the previously focused node, desktop or monitor.
+func Last() Selector {
+	return Selector{descriptor: "last"}
+}
+
+// Newest matches the newest node, desktop or monitor in the history.
+func Newest() Selector {
+	return Selector{descriptor: "newest"}
+}
+
+// Older matches the node, desktop or monitor that comes before the reference, in the history.
+func Older() Selector {
+	return Selector{descriptor: "older"}
+}
+
+// Newer matches the node, desktop or monitor that comes after the reference, in the history.
+func Newer() Selector {
+	return Selector{descriptor: "newer"}
+}
+
+// Biggest matches the biggest leaf node.
+func Biggest() Selector {
+	return Selector{descriptor: "biggest"}
+}
+
+// Smallest matches the smallest leaf node.
+func Smallest() Selector {
+	return Selector{descriptor: "smallest"}
+}
+
+// With returns a copy of the selector, with the given modifiers appended.
+func (s Selector) With(mods ...Modifier) Selector {
+	s.modifiers = append(append([]Modifier(nil), s.modifiers...), mods...)
+	return s
+}
+
+// RelativeTo returns a copy of the selector, whose descriptor is resolved relative to the reference.
+func (s Selector) RelativeTo(ref Selector) Selector {
+	s.reference = &ref
+	return s
+}
+
+// IsZero returns true if the selector is empty.
+func (s Selector) IsZero() bool {
+	return s.reference == nil && s.descriptor == "" && len(s.modifiers) == 0
+}
+
+// String renders the selector in bspwm's syntax.
+func (s Selector) String() string {
+	var sb strings.Builder
+
+	if s.reference != nil {
+		sb.WriteString(s.reference.String())
+		sb.WriteString("#")
+	}
+
+	sb.WriteString(s.descriptor)
+
+	for _, m := range s.modifiers {
+		sb.WriteString(".")
+		sb.WriteString(string(m))
+	}
+
+	return sb.String()
+}
+
+// ParseSelector parses a selector written in bspwm's syntax.
+// Its String method renders it back into the exact same string.
+// Only the syntax is checked: whether the descriptor and modifiers exist is left to bspwm.
+func ParseSelector(sel string) (Selector, error) {
+	if sel == "" {
+		return Selector{}, fmt.Errorf("%w: empty selector", ErrInvalidSelector)
+	}
+
+	var s Selector
+
+	if i := strings.LastIndex(sel, "#"); i >= 0 {
+		ref, err := ParseSelector(sel[:i])
+		if err != nil {
+			return Selector{}, err
+		}
+
+		s.reference = &ref
+		sel = sel[i+1:]
+	}
+
+	parts := strings.Split(sel, ".")
+
+	s.descriptor = parts[0]
+	if s.descriptor == "" && len(parts) == 1 {
+		return Selector{}, fmt.Errorf("%w: missing descriptor", ErrInvalidSelector)
+	}
+
+	for _, m := range parts[1:] {
+		if m == "" || m == "!" {
+			return Selector{}, fmt.Errorf("%w: empty modifier in '%s'", ErrInvalidSelector, sel)
+		}
+
+		s.modifiers = append(s.modifiers, Modifier(m))
+	}
+
+	return s, nil
+}
+
+// MustParseSelector works like ParseSelector, but panics if the selector is invalid.
+// It is meant for selectors known at compile time.
+func MustParseSelector(sel string) Selector {
+	s, err := ParseSelector(sel)
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
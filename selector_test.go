@@ -0,0 +1,93 @@
+package bspc_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diogox/bspc-go"
+)
+
+func TestSelector_String(t *testing.T) {
+	tests := map[string]struct {
+		sel  bspc.Selector
+		want string
+	}{
+		"should render an empty selector": {
+			sel:  bspc.Selector{},
+			want: "",
+		},
+		"should render IDs in bspwm's hex format": {
+			sel:  bspc.ByID(bspc.ID(0x00E00003)),
+			want: "0x00E00003",
+		},
+		"should render modifiers and negations": {
+			sel:  bspc.Focused().With(bspc.Not(bspc.StateModifier(bspc.StateTypeFloating)), bspc.ModifierLocal),
+			want: "focused.!floating.local",
+		},
+		"should render references": {
+			sel:  bspc.Direction(bspc.DirectionTypeLeft).RelativeTo(bspc.Last()).With(bspc.ModifierWindow),
+			want: "last#west.window",
+		},
+		"should render paths on a desktop": {
+			sel:  bspc.PathOn(bspc.FocusedOn(bspc.Index(1)), "/1/2"),
+			want: "@^1:focused:/1/2",
+		},
+		"should add the missing path prefix": {
+			sel:  bspc.Path("/1/2"),
+			want: "@/1/2",
+		},
+		"should render modifiers without a descriptor": {
+			sel:  bspc.Selector{}.With(bspc.FlagModifier(bspc.FlagTypeHidden), bspc.ModifierWindow),
+			want: ".hidden.window",
+		},
+		"should render cycles with desktop modifiers": {
+			sel:  bspc.Cycle(bspc.CycleDirTypeNext).With(bspc.ModifierOccupied, bspc.Not(bspc.UserLayoutModifier(bspc.LayoutTypeMonocle))),
+			want: "next.occupied.!user_monocle",
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.sel.String())
+		})
+	}
+
+	t.Run("should not share modifiers between copies", func(t *testing.T) {
+		base := bspc.Focused().With(bspc.ModifierLocal)
+		a := base.With(bspc.ModifierLeaf)
+		b := base.With(bspc.ModifierWindow)
+
+		assert.Equal(t, "focused.local.leaf", a.String())
+		assert.Equal(t, "focused.local.window", b.String())
+	})
+}
+
+func TestParseSelector(t *testing.T) {
+	t.Run("should round-trip selectors", func(t *testing.T) {
+		for _, s := range []string{
+			"focused",
+			"focused.!floating.local",
+			"@^1:focused",
+			"@/1/2",
+			"0x00E00003#west.!hidden",
+			"last#newer#next.local",
+			".leaf.!window",
+			"^2",
+		} {
+			sel, err := bspc.ParseSelector(s)
+			require.NoError(t, err, s)
+			assert.Equal(t, s, sel.String())
+		}
+	})
+
+	t.Run("should reject malformed selectors", func(t *testing.T) {
+		for _, s := range []string{"", "focused..local", "focused.!", "#focused", "."} {
+			_, err := bspc.ParseSelector(s)
+			assert.True(t, errors.Is(err, bspc.ErrInvalidSelector), s)
+		}
+	})
+}
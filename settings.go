@@ -0,0 +1,335 @@
+package bspc
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+)
+
+type (
+	// SettingType is the type of the values a setting holds.
+	SettingType string
+
+	// SettingScope is where a setting can be applied. Global settings apply to everything
+	// that doesn't override them.
+	SettingScope string
+
+	// Setting describes one of bspwm's settings. Its values are checked against its type before being
+	// sent, and parsed into the matching Go type when read:
+	// int for SettingTypeInt, bool for SettingTypeBool, float64 for SettingTypeFloat, string for
+	// SettingTypeColor and SettingTypeString, and the enum's type for SettingTypeEnum and SettingTypePointerModifier.
+	Setting struct {
+		Name   string
+		Type   SettingType
+		Scopes []SettingScope
+
+		// enum returns the value as the enum's type, and whether it is valid.
+		enum func(value string) (interface{}, bool)
+	}
+)
+
+const (
+	SettingTypeInt             SettingType = "int"
+	SettingTypeBool            SettingType = "bool"
+	SettingTypeFloat           SettingType = "float"
+	SettingTypeColor           SettingType = "color"
+	SettingTypeString          SettingType = "string"
+	SettingTypeEnum            SettingType = "enum"
+	SettingTypePointerModifier SettingType = "pointer_modifier"
+
+	SettingScopeGlobal  SettingScope = "global"
+	SettingScopeMonitor SettingScope = "monitor"
+	SettingScopeDesktop SettingScope = "desktop"
+	SettingScopeNode    SettingScope = "node"
+)
+
+var (
+	globalScope   = []SettingScope{SettingScopeGlobal}
+	desktopScopes = []SettingScope{SettingScopeGlobal, SettingScopeMonitor, SettingScopeDesktop}
+	nodeScopes    = []SettingScope{SettingScopeGlobal, SettingScopeMonitor, SettingScopeDesktop, SettingScopeNode}
+)
+
+// Global settings.
+var (
+	SettingNormalBorderColor   = Setting{Name: "normal_border_color", Type: SettingTypeColor, Scopes: globalScope}
+	SettingActiveBorderColor   = Setting{Name: "active_border_color", Type: SettingTypeColor, Scopes: globalScope}
+	SettingFocusedBorderColor  = Setting{Name: "focused_border_color", Type: SettingTypeColor, Scopes: globalScope}
+	SettingPreselFeedbackColor = Setting{Name: "presel_feedback_color", Type: SettingTypeColor, Scopes: globalScope}
+
+	SettingSplitRatio                = Setting{Name: "split_ratio", Type: SettingTypeFloat, Scopes: globalScope}
+	SettingStatusPrefix              = Setting{Name: "status_prefix", Type: SettingTypeString, Scopes: globalScope}
+	SettingExternalRulesCommand      = Setting{Name: "external_rules_command", Type: SettingTypeString, Scopes: globalScope}
+	SettingAutomaticScheme           = Setting{Name: "automatic_scheme", Type: SettingTypeEnum, Scopes: globalScope, enum: automaticSchemeEnum}
+	SettingInitialPolarity           = Setting{Name: "initial_polarity", Type: SettingTypeEnum, Scopes: globalScope, enum: polarityEnum}
+	SettingDirectionalFocusTightness = Setting{Name: "directional_focus_tightness", Type: SettingTypeEnum, Scopes: globalScope, enum: tightnessEnum}
+	SettingRemovalAdjustment         = Setting{Name: "removal_adjustment", Type: SettingTypeBool, Scopes: globalScope}
+	SettingPreselFeedback            = Setting{Name: "presel_feedback", Type: SettingTypeBool, Scopes: globalScope}
+
+	SettingBorderlessMonocle    = Setting{Name: "borderless_monocle", Type: SettingTypeBool, Scopes: globalScope}
+	SettingGaplessMonocle       = Setting{Name: "gapless_monocle", Type: SettingTypeBool, Scopes: globalScope}
+	SettingSingleMonocle        = Setting{Name: "single_monocle", Type: SettingTypeBool, Scopes: globalScope}
+	SettingBorderlessSingleton  = Setting{Name: "borderless_singleton", Type: SettingTypeBool, Scopes: globalScope}
+	SettingTopMonoclePadding    = Setting{Name: "top_monocle_padding", Type: SettingTypeInt, Scopes: globalScope}
+	SettingRightMonoclePadding  = Setting{Name: "right_monocle_padding", Type: SettingTypeInt, Scopes: globalScope}
+	SettingBottomMonoclePadding = Setting{Name: "bottom_monocle_padding", Type: SettingTypeInt, Scopes: globalScope}
+	SettingLeftMonoclePadding   = Setting{Name: "left_monocle_padding", Type: SettingTypeInt, Scopes: globalScope}
+
+	SettingPointerMotionInterval = Setting{Name: "pointer_motion_interval", Type: SettingTypeInt, Scopes: globalScope}
+	SettingPointerModifier       = Setting{Name: "pointer_modifier", Type: SettingTypePointerModifier, Scopes: globalScope, enum: pointerModifierEnum}
+	SettingPointerAction1        = Setting{Name: "pointer_action1", Type: SettingTypeEnum, Scopes: globalScope, enum: pointerActionEnum}
+	SettingPointerAction2        = Setting{Name: "pointer_action2", Type: SettingTypeEnum, Scopes: globalScope, enum: pointerActionEnum}
+	SettingPointerAction3        = Setting{Name: "pointer_action3", Type: SettingTypeEnum, Scopes: globalScope, enum: pointerActionEnum}
+	SettingClickToFocus          = Setting{Name: "click_to_focus", Type: SettingTypeEnum, Scopes: globalScope, enum: clickToFocusEnum}
+	SettingSwallowFirstClick     = Setting{Name: "swallow_first_click", Type: SettingTypeBool, Scopes: globalScope}
+	SettingFocusFollowsPointer   = Setting{Name: "focus_follows_pointer", Type: SettingTypeBool, Scopes: globalScope}
+	SettingPointerFollowsFocus   = Setting{Name: "pointer_follows_focus", Type: SettingTypeBool, Scopes: globalScope}
+	SettingPointerFollowsMonitor = Setting{Name: "pointer_follows_monitor", Type: SettingTypeBool, Scopes: globalScope}
+
+	SettingMappingEventsCount = Setting{Name: "mapping_events_count", Type: SettingTypeInt, Scopes: globalScope}
+	SettingIgnoreEWMHFocus    = Setting{Name: "ignore_ewmh_focus", Type: SettingTypeBool, Scopes: globalScope}
+	// SettingIgnoreEWMHFullscreen is either "none", "all", or a comma separated list of "enter" and "exit".
+	SettingIgnoreEWMHFullscreen = Setting{Name: "ignore_ewmh_fullscreen", Type: SettingTypeString, Scopes: globalScope}
+	SettingIgnoreEWMHStruts     = Setting{Name: "ignore_ewmh_struts", Type: SettingTypeBool, Scopes: globalScope}
+	SettingCenterPseudoTiled    = Setting{Name: "center_pseudo_tiled", Type: SettingTypeBool, Scopes: globalScope}
+	// SettingHonorSizeHints is either "true", "false", "tiled" or "floating", depending on bspwm's version.
+	SettingHonorSizeHints           = Setting{Name: "honor_size_hints", Type: SettingTypeString, Scopes: globalScope}
+	SettingRemoveDisabledMonitors   = Setting{Name: "remove_disabled_monitors", Type: SettingTypeBool, Scopes: globalScope}
+	SettingRemoveUnpluggedMonitors  = Setting{Name: "remove_unplugged_monitors", Type: SettingTypeBool, Scopes: globalScope}
+	SettingMergeOverlappingMonitors = Setting{Name: "merge_overlapping_monitors", Type: SettingTypeBool, Scopes: globalScope}
+)
+
+// Monitor and desktop settings.
+var (
+	SettingTopPadding    = Setting{Name: "top_padding", Type: SettingTypeInt, Scopes: desktopScopes}
+	SettingRightPadding  = Setting{Name: "right_padding", Type: SettingTypeInt, Scopes: desktopScopes}
+	SettingBottomPadding = Setting{Name: "bottom_padding", Type: SettingTypeInt, Scopes: desktopScopes}
+	SettingLeftPadding   = Setting{Name: "left_padding", Type: SettingTypeInt, Scopes: desktopScopes}
+)
+
+// Desktop settings.
+var (
+	SettingWindowGap = Setting{Name: "window_gap", Type: SettingTypeInt, Scopes: desktopScopes}
+)
+
+// Node settings.
+var (
+	SettingBorderWidth = Setting{Name: "border_width", Type: SettingTypeInt, Scopes: nodeScopes}
+)
+
+var settings = []Setting{
+	SettingNormalBorderColor,
+	SettingActiveBorderColor,
+	SettingFocusedBorderColor,
+	SettingPreselFeedbackColor,
+	SettingSplitRatio,
+	SettingStatusPrefix,
+	SettingExternalRulesCommand,
+	SettingAutomaticScheme,
+	SettingInitialPolarity,
+	SettingDirectionalFocusTightness,
+	SettingRemovalAdjustment,
+	SettingPreselFeedback,
+	SettingBorderlessMonocle,
+	SettingGaplessMonocle,
+	SettingSingleMonocle,
+	SettingBorderlessSingleton,
+	SettingTopMonoclePadding,
+	SettingRightMonoclePadding,
+	SettingBottomMonoclePadding,
+	SettingLeftMonoclePadding,
+	SettingPointerMotionInterval,
+	SettingPointerModifier,
+	SettingPointerAction1,
+	SettingPointerAction2,
+	SettingPointerAction3,
+	SettingClickToFocus,
+	SettingSwallowFirstClick,
+	SettingFocusFollowsPointer,
+	SettingPointerFollowsFocus,
+	SettingPointerFollowsMonitor,
+	SettingMappingEventsCount,
+	SettingIgnoreEWMHFocus,
+	SettingIgnoreEWMHFullscreen,
+	SettingIgnoreEWMHStruts,
+	SettingCenterPseudoTiled,
+	SettingHonorSizeHints,
+	SettingRemoveDisabledMonitors,
+	SettingRemoveUnpluggedMonitors,
+	SettingMergeOverlappingMonitors,
+	SettingTopPadding,
+	SettingRightPadding,
+	SettingBottomPadding,
+	SettingLeftPadding,
+	SettingWindowGap,
+	SettingBorderWidth,
+}
+
+// colorRegex matches the hexadecimal colors bspwm accepts. It also accepts X11 color names, such as "red".
+var colorRegex = regexp.MustCompile(`^(#[0-9a-fA-F]{6}|#[0-9a-fA-F]{3}|[a-zA-Z][a-zA-Z0-9 ]*)$`)
+
+// Settings returns every known setting.
+func Settings() []Setting {
+	return append([]Setting(nil), settings...)
+}
+
+// LookupSetting returns the setting with the given name, and whether it exists.
+func LookupSetting(name string) (Setting, bool) {
+	for _, s := range settings {
+		if s.Name == name {
+			return s, true
+		}
+	}
+
+	return Setting{}, false
+}
+
+// HasScope returns true if the setting can be applied to the given scope.
+func (s Setting) HasScope(scope SettingScope) bool {
+	for _, sc := range s.Scopes {
+		if sc == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// format validates the value against the setting's type, and formats it the way bspwm expects it.
+func (s Setting) format(value interface{}) (string, error) {
+	invalid := fmt.Errorf("%w: invalid %s value %v for %s", ErrInvalidArgument, s.Type, value, s.Name)
+
+	switch s.Type {
+	case SettingTypeInt:
+		v, ok := value.(int)
+		if !ok {
+			return "", invalid
+		}
+
+		return strconv.Itoa(v), nil
+	case SettingTypeBool:
+		v, ok := value.(bool)
+		if !ok {
+			return "", invalid
+		}
+
+		return strconv.FormatBool(v), nil
+	case SettingTypeFloat:
+		v, ok := value.(float64)
+		if !ok {
+			return "", invalid
+		}
+
+		// bspwm ignores split ratios outside of (0, 1).
+		if s.Name == SettingSplitRatio.Name && (v <= 0 || v >= 1) {
+			return "", invalid
+		}
+
+		return formatFloat(v), nil
+	case SettingTypeColor:
+		v, ok := value.(string)
+		if !ok || !colorRegex.MatchString(v) {
+			return "", invalid
+		}
+
+		return v, nil
+	case SettingTypeString:
+		v, ok := value.(string)
+		if !ok {
+			return "", invalid
+		}
+
+		return v, nil
+	case SettingTypeEnum, SettingTypePointerModifier:
+		// Enums are typed strings, which can't be asserted without knowing their type.
+		rv := reflect.ValueOf(value)
+		if !rv.IsValid() || rv.Kind() != reflect.String {
+			return "", invalid
+		}
+
+		enum := s.enumValidator()
+		if enum == nil {
+			return "", fmt.Errorf("%w: unknown values for %s", ErrInvalidArgument, s.Name)
+		}
+
+		if _, ok := enum(rv.String()); !ok {
+			return "", invalid
+		}
+
+		return rv.String(), nil
+	default:
+		return "", fmt.Errorf("%w: unknown setting type %s", ErrInvalidArgument, s.Type)
+	}
+}
+
+// enumValidator returns the function validating the setting's values, taken from the registry for settings
+// that weren't, or nil if it's unknown.
+func (s Setting) enumValidator() func(value string) (interface{}, bool) {
+	if s.enum != nil {
+		return s.enum
+	}
+
+	if known, ok := LookupSetting(s.Name); ok {
+		return known.enum
+	}
+
+	return nil
+}
+
+// parse parses the value, as bspwm responds with it, into the setting's Go type.
+func (s Setting) parse(raw string) (interface{}, error) {
+	switch s.Type {
+	case SettingTypeInt:
+		return strconv.Atoi(raw)
+	case SettingTypeBool:
+		return strconv.ParseBool(raw)
+	case SettingTypeFloat:
+		return strconv.ParseFloat(raw, 64)
+	case SettingTypeColor, SettingTypeString:
+		return raw, nil
+	case SettingTypeEnum, SettingTypePointerModifier:
+		enum := s.enumValidator()
+		if enum == nil {
+			return nil, fmt.Errorf("%w: unknown values for %s", ErrInvalidArgument, s.Name)
+		}
+
+		v, ok := enum(raw)
+		if !ok {
+			return nil, fmt.Errorf("unknown %s value: %s", s.Name, raw)
+		}
+
+		return v, nil
+	default:
+		return nil, fmt.Errorf("unknown setting type: %s", s.Type)
+	}
+}
+
+func automaticSchemeEnum(value string) (interface{}, bool) {
+	v := AutomaticSchemeType(value)
+	return v, v.IsValid()
+}
+
+func polarityEnum(value string) (interface{}, bool) {
+	v := PolarityType(value)
+	return v, v.IsValid()
+}
+
+func tightnessEnum(value string) (interface{}, bool) {
+	v := TightnessType(value)
+	return v, v.IsValid()
+}
+
+func pointerModifierEnum(value string) (interface{}, bool) {
+	v := PointerModifierType(value)
+	return v, v.IsValid()
+}
+
+func pointerActionEnum(value string) (interface{}, bool) {
+	v := PointerActionSettingType(value)
+	return v, v.IsValid()
+}
+
+func clickToFocusEnum(value string) (interface{}, bool) {
+	v := ClickToFocusType(value)
+	return v, v.IsValid()
+}
@@ -0,0 +1,282 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/diogox/bspc-go"
+)
+
+type (
+	// Report describes what a restore couldn't do.
+	Report struct {
+		// Unmatched are the windows in the snapshot that no open window matched.
+		Unmatched []Unmatched
+		// MissingDesktops are the names of the desktops in the snapshot that don't exist anymore.
+		MissingDesktops []string
+	}
+
+	// Unmatched is a window in the snapshot that no open window matched.
+	Unmatched struct {
+		Desktop  string
+		Class    string
+		Instance string
+	}
+
+	restorer struct {
+		ctx    context.Context
+		client bspc.Client
+		report *Report
+		// windows are the open windows that weren't claimed yet, in the order they're claimed in.
+		windows []window
+	}
+
+	window struct {
+		id        bspc.ID
+		desktopID bspc.ID
+		class     string
+		instance  string
+	}
+)
+
+// receptacles matches every receptacle, in any desktop.
+var receptacles = bspc.Selector{}.With(bspc.ModifierLeaf, bspc.Not(bspc.ModifierWindow))
+
+// Restore rearranges the desktops in the snapshot, found by their names, to match it.
+// The shape of each tree is rebuilt with receptacles and preselections, then open windows are moved into
+// place, from any desktop, preferring the ones already on the right desktop. Receptacles in the snapshot are
+// left in place. Receptacles left without a matching window are removed, and reported. Windows that aren't
+// in the snapshot are left alone.
+// An error is only returned if bspwm rejects a command, in which case the restore stops halfway.
+func Restore(ctx context.Context, c bspc.Client, snap Snapshot) (Report, error) {
+	st, err := c.DumpStateContext(ctx)
+	if err != nil {
+		return Report{}, fmt.Errorf("failed to dump state: %w", err)
+	}
+
+	r := restorer{ctx: ctx, client: c, report: &Report{}, windows: openWindows(st)}
+
+	for _, d := range snap.Desktops {
+		desktop := findDesktop(st, d)
+		if desktop == nil {
+			r.report.MissingDesktops = append(r.report.MissingDesktops, d.Name)
+			continue
+		}
+
+		if err := r.restoreDesktop(d, *desktop); err != nil {
+			return *r.report, fmt.Errorf("failed to restore desktop %s: %w", d.Name, err)
+		}
+	}
+
+	return *r.report, nil
+}
+
+func (r *restorer) restoreDesktop(d Desktop, current bspc.Desktop) error {
+	if d.Layout.IsValid() && d.Layout != current.Layout {
+		if err := r.client.Desktop(bspc.ByID(current.ID)).WithContext(r.ctx).Layout(d.Layout); err != nil {
+			return err
+		}
+	}
+
+	if d.Root == nil {
+		return nil
+	}
+
+	// Next to what's already there, or in place of the empty root.
+	root := r.node(bspc.PathOn(bspc.ByID(current.ID), "/"))
+	if current.Root.ID != bspc.NilID {
+		if err := root.Presel(bspc.DirectionTypeRight); err != nil {
+			return err
+		}
+	}
+
+	receptacle, err := r.insertReceptacle(root)
+	if err != nil {
+		return err
+	}
+
+	return r.build(d, current.ID, d.Root, receptacle)
+}
+
+// build grows the tree from the receptacle, and moves the matching windows into its leaves.
+func (r *restorer) build(d Desktop, desktopID bspc.ID, n *Node, receptacle bspc.ID) error {
+	if n.IsLeaf() {
+		return r.fill(d, desktopID, n, receptacle)
+	}
+
+	// Both directions keep the receptacle as the first child.
+	dir := bspc.DirectionTypeDown
+	if n.SplitType == bspc.SplitTypeVertical {
+		dir = bspc.DirectionTypeRight
+	}
+
+	nc := r.node(bspc.ByID(receptacle))
+	if err := nc.Presel(dir); err != nil {
+		return err
+	}
+
+	if n.SplitRatio > 0 && n.SplitRatio < 1 {
+		if err := nc.PreselRatio(n.SplitRatio); err != nil {
+			return err
+		}
+	}
+
+	second, err := r.insertReceptacle(nc)
+	if err != nil {
+		return err
+	}
+
+	if err := r.build(d, desktopID, n.FirstChild, receptacle); err != nil {
+		return err
+	}
+
+	return r.build(d, desktopID, n.SecondChild, second)
+}
+
+// fill moves the window matching the leaf into the receptacle, or removes the receptacle if there's none.
+// Leaves that were receptacles are kept as they are.
+func (r *restorer) fill(d Desktop, desktopID bspc.ID, leaf *Node, receptacle bspc.ID) error {
+	if leaf.Receptacle {
+		return nil
+	}
+
+	w, ok := r.claim(leaf, desktopID)
+	if !ok {
+		r.report.Unmatched = append(r.report.Unmatched, Unmatched{Desktop: d.Name, Class: leaf.Class, Instance: leaf.Instance})
+		return r.node(bspc.ByID(receptacle)).Kill()
+	}
+
+	nc := r.node(bspc.ByID(w.id))
+	if err := nc.ToNode(bspc.ByID(receptacle), false); err != nil {
+		return err
+	}
+
+	if leaf.State.IsValid() {
+		if err := nc.State(leaf.State); err != nil {
+			return err
+		}
+	}
+
+	if leaf.Layer.IsValid() {
+		if err := nc.Layer(leaf.Layer); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range []struct {
+		flag    bspc.FlagType
+		enabled bool
+	}{
+		{bspc.FlagTypeHidden, leaf.Hidden},
+		{bspc.FlagTypeSticky, leaf.Sticky},
+		{bspc.FlagTypePrivate, leaf.Private},
+		{bspc.FlagTypeLocked, leaf.Locked},
+		{bspc.FlagTypeMarked, leaf.Marked},
+	} {
+		if err := nc.Flag(f.flag, f.enabled); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// claim returns the first unclaimed window matching the leaf, preferring the ones on the given desktop.
+func (r *restorer) claim(leaf *Node, desktopID bspc.ID) (window, bool) {
+	match := -1
+	for i, w := range r.windows {
+		if w.class != leaf.Class || w.instance != leaf.Instance {
+			continue
+		}
+
+		if match == -1 || (w.desktopID == desktopID && r.windows[match].desktopID != desktopID) {
+			match = i
+		}
+	}
+
+	if match == -1 {
+		return window{}, false
+	}
+
+	w := r.windows[match]
+	r.windows = append(r.windows[:match], r.windows[match+1:]...)
+
+	return w, true
+}
+
+// insertReceptacle inserts a receptacle at the node, and returns its ID.
+// bspwm doesn't report it, so it's found by comparing the receptacles before and after.
+func (r *restorer) insertReceptacle(nc bspc.NodeCommand) (bspc.ID, error) {
+	before, err := r.client.QueryNodesContext(r.ctx, receptacles)
+	if err != nil {
+		return bspc.NilID, err
+	}
+
+	if err := nc.InsertReceptacle(); err != nil {
+		return bspc.NilID, err
+	}
+
+	after, err := r.client.QueryNodesContext(r.ctx, receptacles)
+	if err != nil {
+		return bspc.NilID, err
+	}
+
+	known := make(map[bspc.ID]bool, len(before))
+	for _, id := range before {
+		known[id] = true
+	}
+
+	for _, id := range after {
+		if !known[id] {
+			return id, nil
+		}
+	}
+
+	return bspc.NilID, fmt.Errorf("inserted receptacle not found")
+}
+
+func (r *restorer) node(sel bspc.Selector) bspc.NodeCommand {
+	return r.client.Node(sel).WithContext(r.ctx)
+}
+
+// openWindows returns every window in the state, desktop by desktop, from left to right.
+func openWindows(st bspc.State) []window {
+	var windows []window
+	for _, m := range st.Monitors {
+		for _, d := range m.Desktops {
+			for _, l := range d.Root.LeafNodes() {
+				if l.Client == nil {
+					continue
+				}
+
+				windows = append(windows, window{
+					id:        l.ID,
+					desktopID: d.ID,
+					class:     l.Client.ClassName,
+					instance:  l.Client.InstanceName,
+				})
+			}
+		}
+	}
+
+	return windows
+}
+
+// findDesktop returns the desktop with the snapshot desktop's name, preferring the one on the same monitor.
+func findDesktop(st bspc.State, d Desktop) *bspc.Desktop {
+	var found *bspc.Desktop
+	for i := range st.Monitors {
+		m := &st.Monitors[i]
+		for j := range m.Desktops {
+			if m.Desktops[j].Name != d.Name {
+				continue
+			}
+
+			if found == nil || m.Name == d.Monitor {
+				found = &m.Desktops[j]
+			}
+		}
+	}
+
+	return found
+}
@@ -0,0 +1,175 @@
+// Package snapshot saves the arrangement of windows in bspwm desktops, and restores it later.
+//
+// Unlike `bspc wm --load-state`, restoring is selective: it only touches the desktops in the snapshot,
+// and works with windows that were reopened since, matching them by their class and instance.
+// Example usage:
+//
+//	st, err := c.DumpState()
+//	snap := snapshot.FromState(st)
+//	err = snapshot.SaveFile("session.json", snap)
+//	...
+//	snap, err := snapshot.LoadFile("session.json")
+//	report, err := snapshot.Restore(ctx, c, snap)
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/diogox/bspc-go"
+)
+
+type (
+	// Snapshot is the arrangement of windows in some desktops.
+	Snapshot struct {
+		Desktops []Desktop `json:"desktops"`
+	}
+
+	// Desktop is the arrangement of windows in a desktop, which is found by its name when restoring.
+	Desktop struct {
+		Monitor string          `json:"monitor"`
+		Name    string          `json:"name"`
+		Layout  bspc.LayoutType `json:"layout"`
+		// Root is nil for empty desktops.
+		Root *Node `json:"root,omitempty"`
+	}
+
+	// Node is either a window, matched by its class and instance, a receptacle, or the split of two other nodes.
+	Node struct {
+		SplitType   bspc.SplitType `json:"splitType,omitempty"`
+		SplitRatio  float64        `json:"splitRatio,omitempty"`
+		FirstChild  *Node          `json:"firstChild,omitempty"`
+		SecondChild *Node          `json:"secondChild,omitempty"`
+
+		// Receptacle is true for leaves that hold no window, which are restored as receptacles.
+		Receptacle bool `json:"receptacle,omitempty"`
+
+		Class    string         `json:"class,omitempty"`
+		Instance string         `json:"instance,omitempty"`
+		State    bspc.StateType `json:"state,omitempty"`
+		Layer    bspc.LayerType `json:"layer,omitempty"`
+		Hidden   bool           `json:"hidden,omitempty"`
+		Sticky   bool           `json:"sticky,omitempty"`
+		Private  bool           `json:"private,omitempty"`
+		Locked   bool           `json:"locked,omitempty"`
+		Marked   bool           `json:"marked,omitempty"`
+	}
+)
+
+// FromState captures every desktop in the state.
+func FromState(st bspc.State) Snapshot {
+	var snap Snapshot
+	for _, m := range st.Monitors {
+		for _, d := range m.Desktops {
+			snap.Desktops = append(snap.Desktops, FromDesktop(m, d))
+		}
+	}
+
+	return snap
+}
+
+// FromDesktop captures a single desktop, of the given monitor.
+func FromDesktop(m bspc.Monitor, d bspc.Desktop) Desktop {
+	desktop := Desktop{Monitor: m.Name, Name: d.Name, Layout: d.Layout}
+	if d.Root.ID != bspc.NilID {
+		desktop.Root = fromNode(d.Root)
+	}
+
+	return desktop
+}
+
+// IsLeaf returns true if the node is a window, or a receptacle.
+func (n Node) IsLeaf() bool {
+	return n.FirstChild == nil || n.SecondChild == nil
+}
+
+// Leaves returns the leaves of the tree rooted at this node, from left to right.
+func (n *Node) Leaves() []*Node {
+	if n.IsLeaf() {
+		return []*Node{n}
+	}
+
+	return append(n.FirstChild.Leaves(), n.SecondChild.Leaves()...)
+}
+
+// Save writes the snapshot as JSON.
+func Save(w io.Writer, snap Snapshot) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(snap); err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// Load reads a snapshot written by Save.
+func Load(r io.Reader) (Snapshot, error) {
+	var snap Snapshot
+	if err := json.NewDecoder(r).Decode(&snap); err != nil {
+		return Snapshot{}, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+
+	return snap, nil
+}
+
+// SaveFile writes the snapshot into the file, replacing it if it exists.
+func SaveFile(path string, snap Snapshot) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := Save(f, snap); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// LoadFile reads a snapshot written by SaveFile.
+func LoadFile(path string) (Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	defer f.Close()
+
+	return Load(f)
+}
+
+func fromNode(n bspc.Node) *Node {
+	if n.FirstChild != nil && n.SecondChild != nil {
+		return &Node{
+			SplitType:   n.SplitType,
+			SplitRatio:  n.SplitRatio,
+			FirstChild:  fromNode(*n.FirstChild),
+			SecondChild: fromNode(*n.SecondChild),
+		}
+	}
+
+	leaf := &Node{
+		Hidden:  n.Hidden,
+		Sticky:  n.Sticky,
+		Private: n.Private,
+		Locked:  n.Locked,
+		Marked:  n.Marked,
+	}
+
+	c := n.Client
+	if c == nil {
+		leaf.Receptacle = true
+		return leaf
+	}
+
+	leaf.Class = c.ClassName
+	leaf.Instance = c.InstanceName
+	leaf.State = c.State
+	leaf.Layer = c.Layer
+
+	return leaf
+}
@@ -0,0 +1,287 @@
+package snapshot_test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diogox/bspc-go"
+	"github.com/diogox/bspc-go/bspctest"
+	"github.com/diogox/bspc-go/snapshot"
+)
+
+const (
+	monitorID = bspc.ID(0x00200002)
+	desktopI  = bspc.ID(0x00200003)
+	desktopII = bspc.ID(0x00200004)
+	parentID  = bspc.ID(0x00400001)
+	browserID = bspc.ID(0x00E00003)
+	termID    = bspc.ID(0x00E00004)
+)
+
+func window(id bspc.ID, class, instance string) *bspc.Node {
+	return &bspc.Node{
+		ID: id,
+		Client: &bspc.NodeClient{
+			ClassName:    class,
+			InstanceName: instance,
+			State:        bspc.StateTypeTiled,
+			Layer:        bspc.LayerTypeNormal,
+		},
+	}
+}
+
+func testState() bspc.State {
+	return bspc.State{
+		FocusedMonitorID: monitorID,
+		Monitors: []bspc.Monitor{{
+			ID:   monitorID,
+			Name: "eDP-1",
+			Desktops: []bspc.Desktop{
+				{ID: desktopI, Name: "I", Layout: bspc.LayoutTypeTiled},
+				{
+					ID:     desktopII,
+					Name:   "II",
+					Layout: bspc.LayoutTypeTiled,
+					Root: bspc.Node{
+						ID:          parentID,
+						SplitType:   bspc.SplitTypeVertical,
+						SplitRatio:  0.5,
+						FirstChild:  window(browserID, "firefox", "Navigator"),
+						SecondChild: window(termID, "Alacritty", "Alacritty"),
+					},
+				},
+			},
+		}},
+	}
+}
+
+func TestFromState(t *testing.T) {
+	t.Run("should capture the tree shape and the windows of every desktop", func(t *testing.T) {
+		st := testState()
+		st.Monitors[0].Desktops[1].Root.SecondChild.Marked = true
+
+		snap := snapshot.FromState(st)
+
+		assert.Equal(t, snapshot.Snapshot{Desktops: []snapshot.Desktop{
+			{Monitor: "eDP-1", Name: "I", Layout: bspc.LayoutTypeTiled},
+			{
+				Monitor: "eDP-1",
+				Name:    "II",
+				Layout:  bspc.LayoutTypeTiled,
+				Root: &snapshot.Node{
+					SplitType:  bspc.SplitTypeVertical,
+					SplitRatio: 0.5,
+					FirstChild: &snapshot.Node{
+						Class:    "firefox",
+						Instance: "Navigator",
+						State:    bspc.StateTypeTiled,
+						Layer:    bspc.LayerTypeNormal,
+					},
+					SecondChild: &snapshot.Node{
+						Class:    "Alacritty",
+						Instance: "Alacritty",
+						State:    bspc.StateTypeTiled,
+						Layer:    bspc.LayerTypeNormal,
+						Marked:   true,
+					},
+				},
+			},
+		}}, snap)
+	})
+}
+
+func TestFromDesktop(t *testing.T) {
+	t.Run("should mark the leaves without a window as receptacles", func(t *testing.T) {
+		st := testState()
+		d := st.Monitors[0].Desktops[1]
+		d.Root.SecondChild = &bspc.Node{ID: 0x00600001}
+
+		desktop := snapshot.FromDesktop(st.Monitors[0], d)
+
+		assert.Equal(t, &snapshot.Node{Receptacle: true}, desktop.Root.SecondChild)
+		assert.False(t, desktop.Root.FirstChild.Receptacle)
+	})
+}
+
+func TestSaveLoad(t *testing.T) {
+	snap := snapshot.FromState(testState())
+
+	t.Run("should read back what was written", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, snapshot.Save(&buf, snap))
+
+		loaded, err := snapshot.Load(&buf)
+		require.NoError(t, err)
+		assert.Equal(t, snap, loaded)
+	})
+
+	t.Run("should read back what was written to a file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "session.json")
+		require.NoError(t, snapshot.SaveFile(path, snap))
+
+		loaded, err := snapshot.LoadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, snap, loaded)
+	})
+
+	t.Run("should fail on malformed snapshots", func(t *testing.T) {
+		_, err := snapshot.Load(bytes.NewBufferString("{"))
+		assert.Error(t, err)
+	})
+}
+
+func TestRestore(t *testing.T) {
+	const receptaclesQuery = "query --nodes --node .leaf.!window"
+
+	// receptacles responds to each query with the next set of receptacles.
+	receptacles := func(responses ...string) func() string {
+		var mu sync.Mutex
+		return func() string {
+			mu.Lock()
+			defer mu.Unlock()
+
+			if len(responses) == 0 {
+				return ""
+			}
+
+			res := responses[0]
+			responses = responses[1:]
+
+			return res
+		}
+	}
+
+	t.Run("should rebuild the tree and move the matching windows into it", func(t *testing.T) {
+		srv := bspctest.NewServer(t)
+		srv.RespondJSON("wm --dump-state", testState())
+		srv.RespondFunc(receptaclesQuery, receptacles(
+			"",
+			"0x00600001\n",
+			"0x00600001\n",
+			"0x00600001\n0x00600002\n",
+			"0x00600002\n",
+			"0x00600002\n0x00600003\n",
+		))
+
+		c, err := bspc.NewWithSocketPath(srv.SocketPath(), nil)
+		require.NoError(t, err)
+
+		snap := snapshot.Snapshot{Desktops: []snapshot.Desktop{
+			{
+				Name:   "I",
+				Layout: bspc.LayoutTypeMonocle,
+				Root: &snapshot.Node{
+					SplitType:  bspc.SplitTypeVertical,
+					SplitRatio: 0.6,
+					FirstChild: &snapshot.Node{Class: "firefox", Instance: "Navigator", State: bspc.StateTypeTiled},
+					SecondChild: &snapshot.Node{
+						SplitType:   bspc.SplitTypeHorizontal,
+						SplitRatio:  0.5,
+						FirstChild:  &snapshot.Node{Class: "Alacritty", Instance: "Alacritty", Sticky: true},
+						SecondChild: &snapshot.Node{Class: "mpv", Instance: "gl"},
+					},
+				},
+			},
+			{Name: "III"},
+		}}
+
+		report, err := snapshot.Restore(context.Background(), c, snap)
+		require.NoError(t, err)
+
+		assert.Equal(t, snapshot.Report{
+			Unmatched:       []snapshot.Unmatched{{Desktop: "I", Class: "mpv", Instance: "gl"}},
+			MissingDesktops: []string{"III"},
+		}, report)
+
+		assert.Equal(t, []string{
+			"wm --dump-state",
+			"desktop 0x00200003 --layout monocle",
+			receptaclesQuery,
+			"node @0x00200003:/ --insert-receptacle",
+			receptaclesQuery,
+			"node 0x00600001 --presel-dir east",
+			"node 0x00600001 --presel-ratio 0.6",
+			receptaclesQuery,
+			"node 0x00600001 --insert-receptacle",
+			receptaclesQuery,
+			"node 0x00E00003 --to-node 0x00600001",
+			"node 0x00E00003 --state tiled",
+			"node 0x00E00003 --flag hidden=off",
+			"node 0x00E00003 --flag sticky=off",
+			"node 0x00E00003 --flag private=off",
+			"node 0x00E00003 --flag locked=off",
+			"node 0x00E00003 --flag marked=off",
+			"node 0x00600002 --presel-dir south",
+			"node 0x00600002 --presel-ratio 0.5",
+			receptaclesQuery,
+			"node 0x00600002 --insert-receptacle",
+			receptaclesQuery,
+			"node 0x00E00004 --to-node 0x00600002",
+			"node 0x00E00004 --flag hidden=off",
+			"node 0x00E00004 --flag sticky=on",
+			"node 0x00E00004 --flag private=off",
+			"node 0x00E00004 --flag locked=off",
+			"node 0x00E00004 --flag marked=off",
+			"node 0x00600003 --kill",
+		}, srv.Commands())
+	})
+
+	t.Run("should recreate the receptacles, instead of filling them", func(t *testing.T) {
+		c, srv := bspctest.NewClient(t)
+		srv.RespondJSON("wm --dump-state", testState())
+		srv.RespondFunc(receptaclesQuery, receptacles(
+			"",
+			"0x00600001\n",
+			"0x00600001\n",
+			"0x00600001\n0x00600002\n",
+		))
+
+		snap := snapshot.Snapshot{Desktops: []snapshot.Desktop{
+			{
+				Name: "I",
+				Root: &snapshot.Node{
+					SplitType:   bspc.SplitTypeHorizontal,
+					FirstChild:  &snapshot.Node{Receptacle: true},
+					SecondChild: &snapshot.Node{Receptacle: true},
+				},
+			},
+		}}
+
+		report, err := snapshot.Restore(context.Background(), c, snap)
+		require.NoError(t, err)
+
+		assert.Equal(t, snapshot.Report{}, report)
+		assert.Equal(t, []string{
+			"wm --dump-state",
+			receptaclesQuery,
+			"node @0x00200003:/ --insert-receptacle",
+			receptaclesQuery,
+			"node 0x00600001 --presel-dir south",
+			receptaclesQuery,
+			"node 0x00600001 --insert-receptacle",
+			receptaclesQuery,
+		}, srv.Commands())
+	})
+
+	t.Run("should stop at the first command bspwm rejects", func(t *testing.T) {
+		srv := bspctest.NewServer(t)
+		srv.RespondJSON("wm --dump-state", testState())
+		srv.Fail("node @0x00200004:/ --presel-dir east", "node: Invalid descriptor found in '@0x00200004:/'.")
+
+		c, err := bspc.NewWithSocketPath(srv.SocketPath(), nil)
+		require.NoError(t, err)
+
+		snap := snapshot.Snapshot{Desktops: []snapshot.Desktop{
+			{Name: "II", Root: &snapshot.Node{Class: "firefox", Instance: "Navigator"}},
+		}}
+
+		_, err = snapshot.Restore(context.Background(), c, snap)
+		assert.ErrorIs(t, err, bspc.ErrInvalidSelector)
+	})
+}
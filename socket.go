@@ -0,0 +1,181 @@
+package bspc
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// socketEnvVar is the environment variable bspc reads the socket path from, before anything else.
+	socketEnvVar = "BSPWM_SOCKET"
+	// socketNameTemplate is the template bspwm names its socket with: host, display and screen.
+	socketNameTemplate = "bspwm%s_%d_%d-socket"
+	// socketGlob matches every socket named with the template above.
+	socketGlob = "bspwm*_*_*-socket"
+)
+
+var (
+	// ErrNoSocket is returned when no bspwm socket could be found.
+	ErrNoSocket = errors.New("no bspwm socket found")
+
+	// socketDir is the directory bspwm creates its socket in. Tests point it somewhere else.
+	socketDir = "/tmp"
+
+	socketNameRegex = regexp.MustCompile(`^bspwm(.*)_(\d+)_(\d+)-socket$`)
+)
+
+type (
+	// Display identifies an X display, and thereby the bspwm instance that manages it.
+	Display struct {
+		Host   string
+		Number int
+		Screen int
+	}
+
+	// Socket is the unix socket of a running bspwm instance.
+	Socket struct {
+		Path    string
+		Display Display
+	}
+)
+
+// ParseDisplay parses a display name, as found in $DISPLAY: [protocol/][host]:number[.screen]
+func ParseDisplay(name string) (Display, error) {
+	if i := strings.Index(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+
+	colon := strings.LastIndex(name, ":")
+	if colon < 0 {
+		return Display{}, fmt.Errorf("invalid display name '%s': missing display number", name)
+	}
+
+	d := Display{Host: name[:colon]}
+
+	numbers := strings.SplitN(name[colon+1:], ".", 2)
+
+	number, err := strconv.Atoi(numbers[0])
+	if err != nil || number < 0 {
+		return Display{}, fmt.Errorf("invalid display name '%s': invalid display number", name)
+	}
+	d.Number = number
+
+	if len(numbers) == 2 {
+		screen, err := strconv.Atoi(numbers[1])
+		if err != nil || screen < 0 {
+			return Display{}, fmt.Errorf("invalid display name '%s': invalid screen number", name)
+		}
+		d.Screen = screen
+	}
+
+	return d, nil
+}
+
+// String returns the display's full name: host:number.screen
+func (d Display) String() string {
+	return fmt.Sprintf("%s:%d.%d", d.Host, d.Number, d.Screen)
+}
+
+// SocketPath returns the path bspwm creates its socket at, when managing this display.
+func (d Display) SocketPath() string {
+	return filepath.Join(socketDir, fmt.Sprintf(socketNameTemplate, d.Host, d.Number, d.Screen))
+}
+
+// DefaultSocketPath finds the socket of the bspwm instance bspc would talk to:
+// the one in $BSPWM_SOCKET, or else the one managing $DISPLAY.
+// If neither exists, it falls back to the first socket found in /tmp.
+func DefaultSocketPath() (string, error) {
+	if path := os.Getenv(socketEnvVar); path != "" {
+		return path, nil
+	}
+
+	if d, err := ParseDisplay(os.Getenv("DISPLAY")); err == nil {
+		if path := d.SocketPath(); isSocket(path) {
+			return path, nil
+		}
+	}
+
+	sockets, err := DiscoverSockets()
+	if err != nil {
+		return "", err
+	}
+
+	if len(sockets) == 0 {
+		return "", ErrNoSocket
+	}
+
+	return sockets[0].Path, nil
+}
+
+// DiscoverSockets returns the sockets of every bspwm instance running on this machine,
+// sorted by display. It doesn't search subdirectories.
+func DiscoverSockets() ([]Socket, error) {
+	paths, err := filepath.Glob(filepath.Join(socketDir, socketGlob))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search for bspwm sockets: %v", err)
+	}
+
+	sockets := make([]Socket, 0, len(paths))
+	for _, path := range paths {
+		if !isSocket(path) {
+			continue
+		}
+
+		d, ok := socketDisplay(path)
+		if !ok {
+			continue
+		}
+
+		sockets = append(sockets, Socket{Path: path, Display: d})
+	}
+
+	sort.Slice(sockets, func(i, j int) bool {
+		a, b := sockets[i].Display, sockets[j].Display
+		if a.Host != b.Host {
+			return a.Host < b.Host
+		}
+
+		if a.Number != b.Number {
+			return a.Number < b.Number
+		}
+
+		return a.Screen < b.Screen
+	})
+
+	return sockets, nil
+}
+
+// socketDisplay extracts the display from the name of a socket built with bspwm's template.
+func socketDisplay(path string) (Display, bool) {
+	matches := socketNameRegex.FindStringSubmatch(filepath.Base(path))
+	if matches == nil {
+		return Display{}, false
+	}
+
+	number, err := strconv.Atoi(matches[2])
+	if err != nil {
+		return Display{}, false
+	}
+
+	screen, err := strconv.Atoi(matches[3])
+	if err != nil {
+		return Display{}, false
+	}
+
+	return Display{Host: matches[1], Number: number, Screen: screen}, true
+}
+
+func isSocket(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeSocket != 0
+}
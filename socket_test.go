@@ -0,0 +1,182 @@
+package bspc_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diogox/bspc-go"
+	"github.com/diogox/bspc-go/bspctest"
+)
+
+func TestParseDisplay(t *testing.T) {
+	tests := map[string]struct {
+		name       string
+		want       bspc.Display
+		wantSocket string
+	}{
+		"should parse a local display": {
+			name:       ":0",
+			want:       bspc.Display{Number: 0},
+			wantSocket: "/tmp/bspwm_0_0-socket",
+		},
+		"should parse the host and screen": {
+			name:       "localhost:10.1",
+			want:       bspc.Display{Host: "localhost", Number: 10, Screen: 1},
+			wantSocket: "/tmp/bspwmlocalhost_10_1-socket",
+		},
+		"should ignore the protocol": {
+			name:       "unix/:2",
+			want:       bspc.Display{Number: 2},
+			wantSocket: "/tmp/bspwm_2_0-socket",
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			d, err := bspc.ParseDisplay(tt.name)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, d)
+			assert.Equal(t, tt.wantSocket, d.SocketPath())
+		})
+	}
+
+	t.Run("should reject invalid display names", func(t *testing.T) {
+		for _, name := range []string{"", "localhost", ":x", ":0.x", ":-1"} {
+			_, err := bspc.ParseDisplay(name)
+			assert.Error(t, err, name)
+		}
+	})
+}
+
+func TestDiscoverSockets(t *testing.T) {
+	t.Run("should return the sockets sorted by display", func(t *testing.T) {
+		dir := socketDir(t)
+		listen(t, dir, "bspwm_1_0-socket")
+		listen(t, dir, "bspwmlocalhost_0_0-socket")
+		listen(t, dir, "bspwm_0_1-socket")
+		listen(t, dir, "bspwm_0_0-socket")
+
+		sockets, err := bspc.DiscoverSockets()
+		require.NoError(t, err)
+
+		assert.Equal(t, []bspc.Socket{
+			{Path: filepath.Join(dir, "bspwm_0_0-socket"), Display: bspc.Display{Number: 0}},
+			{Path: filepath.Join(dir, "bspwm_0_1-socket"), Display: bspc.Display{Number: 0, Screen: 1}},
+			{Path: filepath.Join(dir, "bspwm_1_0-socket"), Display: bspc.Display{Number: 1}},
+			{Path: filepath.Join(dir, "bspwmlocalhost_0_0-socket"), Display: bspc.Display{Host: "localhost"}},
+		}, sockets)
+	})
+
+	t.Run("should skip files that aren't bspwm sockets", func(t *testing.T) {
+		dir := socketDir(t)
+		listen(t, dir, "bspwm_0_0-socket")
+		listen(t, dir, "bspwm_x_0-socket")
+		listen(t, dir, "other-socket")
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "bspwm_1_0-socket"), nil, 0600))
+
+		sockets, err := bspc.DiscoverSockets()
+		require.NoError(t, err)
+
+		assert.Equal(t, []bspc.Socket{{Path: filepath.Join(dir, "bspwm_0_0-socket")}}, sockets)
+	})
+}
+
+func TestDefaultSocketPath(t *testing.T) {
+	t.Run("should use the socket of the display in DISPLAY", func(t *testing.T) {
+		dir := socketDir(t)
+		listen(t, dir, "bspwm_0_0-socket")
+		listen(t, dir, "bspwm_1_0-socket")
+
+		setenv(t, "BSPWM_SOCKET", "")
+		setenv(t, "DISPLAY", ":1")
+
+		path, err := bspc.DefaultSocketPath()
+		require.NoError(t, err)
+
+		assert.Equal(t, filepath.Join(dir, "bspwm_1_0-socket"), path)
+	})
+
+	t.Run("should fall back to the first socket found, when the display has none", func(t *testing.T) {
+		dir := socketDir(t)
+		listen(t, dir, "bspwm_1_0-socket")
+		listen(t, dir, "bspwm_0_0-socket")
+
+		setenv(t, "BSPWM_SOCKET", "")
+		setenv(t, "DISPLAY", ":2")
+
+		path, err := bspc.DefaultSocketPath()
+		require.NoError(t, err)
+
+		assert.Equal(t, filepath.Join(dir, "bspwm_0_0-socket"), path)
+	})
+
+	t.Run("should fail when there are no sockets", func(t *testing.T) {
+		socketDir(t)
+
+		setenv(t, "BSPWM_SOCKET", "")
+		setenv(t, "DISPLAY", ":0")
+
+		_, err := bspc.DefaultSocketPath()
+		assert.True(t, errors.Is(err, bspc.ErrNoSocket))
+	})
+}
+
+func TestNew(t *testing.T) {
+	t.Run("should use the socket in BSPWM_SOCKET", func(t *testing.T) {
+		srv := bspctest.NewServer(t)
+		srv.Respond("query --desktops --desktop focused --names", "I\n")
+
+		setenv(t, "BSPWM_SOCKET", srv.SocketPath())
+
+		c, err := bspc.New(nil)
+		require.NoError(t, err)
+
+		names, err := c.QueryDesktopNames(bspc.Focused())
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"I"}, names)
+	})
+}
+
+func setenv(t *testing.T, key, value string) {
+	previous, ok := os.LookupEnv(key)
+	require.NoError(t, os.Setenv(key, value))
+
+	t.Cleanup(func() {
+		if ok {
+			_ = os.Setenv(key, previous)
+			return
+		}
+
+		_ = os.Unsetenv(key)
+	})
+}
+
+// socketDir makes the socket lookups search an empty temporary directory, until the test finishes.
+func socketDir(t *testing.T) string {
+	// Unix socket paths are limited to around a hundred bytes, which the directories
+	// created by t.TempDir can exceed.
+	dir, err := ioutil.TempDir("", "bspwm-sockets")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	bspc.SetSocketDir(t, dir)
+
+	return dir
+}
+
+func listen(t *testing.T, dir, name string) {
+	l, err := net.Listen("unix", filepath.Join(dir, name))
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = l.Close() })
+}
@@ -21,7 +21,7 @@ type (
 // the slice flows from the most recently focused node, to the oldest.
 func (s State) OrderedFocusHistory() []StateFocusHistoryEntry {
 	inverted := make([]StateFocusHistoryEntry, 0, len(s.FocusHistory))
-	for i := len(s.FocusHistory); i > len(s.FocusHistory); i-- {
+	for i := len(s.FocusHistory) - 1; i >= 0; i-- {
 		inverted = append(inverted, s.FocusHistory[i])
 	}
 
@@ -21,8 +21,9 @@ func TestState_OrderedFocusHistory(t *testing.T) {
 		}
 
 		ordered := s.OrderedFocusHistory()
+		assert.Len(t, ordered, len(s.FocusHistory))
 		for want, got := range ordered {
-			assert.Equal(t, want, got.NodeID)
+			assert.Equal(t, bspc.ID(want), got.NodeID)
 		}
 	})
 }
@@ -0,0 +1,62 @@
+package bspc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrSubscriptionEnded is returned when a subscription ends without an error, before its context is done.
+var ErrSubscriptionEnded = errors.New("event subscription ended")
+
+// ConsumeEvents calls fn with each event of a subscription, as returned by the client's SubscribeEventsContext,
+// until the context is done or the subscription ends. It blocks, and always returns a non-nil error:
+// the context's, the one the subscription ended with, or ErrSubscriptionEnded.
+// Errors returned by fn are only logged, as events often concern windows that went away since.
+// If the value passed in as a logger is nil, logging will be disabled.
+// Example usage:
+//
+//	eventCh, errCh, err := c.SubscribeEventsContext(ctx, bspc.EventTypeNodeAdd)
+//	...
+//	err = bspc.ConsumeEvents(ctx, eventCh, errCh, logger, func(ev bspc.Event) error {
+//		...
+//		return nil
+//	})
+func ConsumeEvents(ctx context.Context, eventCh <-chan Event, errCh <-chan error, logger Logger, fn func(Event) error) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ev, ok := <-eventCh:
+			if !ok {
+				return subscriptionEnded(ctx, errCh)
+			}
+
+			if err := fn(ev); err != nil && logger != nil {
+				logger.Warn(fmt.Sprintf(`"%s" event - %v`, ev.Type, err))
+			}
+		case err, ok := <-errCh:
+			if ok {
+				return err
+			}
+
+			errCh = nil
+		}
+	}
+}
+
+// subscriptionEnded returns why a subscription ended, once its events channel is closed.
+// The errors channel must be nil if it was seen closed already, as receiving from it would block forever.
+func subscriptionEnded(ctx context.Context, errCh <-chan error) error {
+	if errCh != nil {
+		if err, ok := <-errCh; ok {
+			return err
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return ErrSubscriptionEnded
+}
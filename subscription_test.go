@@ -0,0 +1,64 @@
+package bspc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/diogox/bspc-go"
+)
+
+type warnings []string
+
+func (w *warnings) Info(string) {}
+
+func (w *warnings) Warn(msg string) {
+	*w = append(*w, msg)
+}
+
+func TestConsumeEvents(t *testing.T) {
+	t.Run("should log the callback's errors and keep going", func(t *testing.T) {
+		eventCh := make(chan bspc.Event, 2)
+		errCh := make(chan error)
+
+		eventCh <- bspc.Event{Type: bspc.EventTypeNodeAdd}
+		eventCh <- bspc.Event{Type: bspc.EventTypeNodeRemove}
+		close(eventCh)
+		close(errCh)
+
+		var (
+			logged warnings
+			seen   []bspc.EventType
+		)
+
+		err := bspc.ConsumeEvents(context.Background(), eventCh, errCh, &logged, func(ev bspc.Event) error {
+			seen = append(seen, ev.Type)
+			return errors.New("window went away")
+		})
+
+		assert.True(t, errors.Is(err, bspc.ErrSubscriptionEnded))
+		assert.Equal(t, []bspc.EventType{bspc.EventTypeNodeAdd, bspc.EventTypeNodeRemove}, seen)
+		assert.Equal(t, warnings{`"node_add" event - window went away`, `"node_remove" event - window went away`}, logged)
+	})
+
+	t.Run("should return the error the subscription ended with", func(t *testing.T) {
+		eventCh := make(chan bspc.Event)
+		errCh := make(chan error, 1)
+
+		errCh <- errors.New("connection reset")
+		close(eventCh)
+
+		err := bspc.ConsumeEvents(context.Background(), eventCh, errCh, nil, func(bspc.Event) error { return nil })
+		assert.EqualError(t, err, "connection reset")
+	})
+
+	t.Run("should return the context's error once it's done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := bspc.ConsumeEvents(ctx, make(chan bspc.Event), make(chan error), nil, func(bspc.Event) error { return nil })
+		assert.True(t, errors.Is(err, context.Canceled))
+	})
+}
@@ -0,0 +1,802 @@
+package bspc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// resyncAttempts is how many times a resync subscribes and dumps the state, while the state keeps changing.
+	resyncAttempts = 5
+	// resyncBackoff is the wait before the second attempt of a resync. It doubles after each attempt.
+	resyncBackoff = 20 * time.Millisecond
+)
+
+// trackedEvents are the events a StateTracker applies to its state.
+var trackedEvents = []EventType{
+	EventTypeMonitorAdd,
+	EventTypeMonitorRename,
+	EventTypeMonitorRemove,
+	EventTypeMonitorSwap,
+	EventTypeMonitorFocus,
+	EventTypeMonitorGeometry,
+	EventTypeDesktopAdd,
+	EventTypeDesktopRename,
+	EventTypeDesktopRemove,
+	EventTypeDesktopSwap,
+	EventTypeDesktopTransfer,
+	EventTypeDesktopFocus,
+	EventTypeDesktopActivate,
+	EventTypeDesktopLayout,
+	EventTypeNodeAdd,
+	EventTypeNodeRemove,
+	EventTypeNodeSwap,
+	EventTypeNodeTransfer,
+	EventTypeNodeFocus,
+	EventTypeNodeActivate,
+	EventTypeNodeStack,
+	EventTypeNodeGeometry,
+	EventTypeNodeState,
+	EventTypeNodeFlag,
+	EventTypeNodeLayer,
+}
+
+// StateTracker keeps an in-memory State in sync with bspwm. It dumps the state once,
+// and then applies the monitor, desktop and node events to it, as they are published.
+//
+// Most events are applied to the tree directly. The ones that create monitors, desktops or nodes
+// don't carry enough info for that (bspwm creates internal nodes whose IDs aren't published), so
+// the affected monitor or desktop is queried again instead.
+// The geometry of tiled windows is only updated by node_geometry events, and by those queries.
+// A periodic resync with a fresh dump fixes whatever the events couldn't.
+// Every dump comes with a new subscription, so that each event is applied to the dumps that don't reflect it,
+// and only to those.
+type StateTracker struct {
+	client         Client
+	logger         Logger
+	resyncInterval time.Duration
+
+	mu    sync.RWMutex
+	state State
+
+	callbacksMu sync.Mutex
+	onChange    []func(State)
+	onDrift     []func(tracked, dumped State)
+}
+
+// NewStateTracker returns a tracker for the bspwm instance behind the client.
+// If resyncInterval is positive, the state is dumped again with that frequency.
+// If the value passed in as a logger is nil, logging will be disabled.
+func NewStateTracker(c Client, logger Logger, resyncInterval time.Duration) *StateTracker {
+	return &StateTracker{
+		client:         c,
+		logger:         logger,
+		resyncInterval: resyncInterval,
+	}
+}
+
+// OnChange registers a callback, called with a snapshot of the state every time it changes.
+// Callbacks run in the tracker's goroutine, so they should return quickly.
+func (t *StateTracker) OnChange(fn func(State)) {
+	t.callbacksMu.Lock()
+	defer t.callbacksMu.Unlock()
+
+	t.onChange = append(t.onChange, fn)
+}
+
+// OnDrift registers a callback, called when a periodic resync finds that the tracked state
+// differs from the one dumped by bspwm. The dumped state replaces the tracked one afterwards.
+func (t *StateTracker) OnDrift(fn func(tracked, dumped State)) {
+	t.callbacksMu.Lock()
+	defer t.callbacksMu.Unlock()
+
+	t.onDrift = append(t.onDrift, fn)
+}
+
+// Snapshot returns a copy of the current state, that is safe to keep and modify.
+func (t *StateTracker) Snapshot() State {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.state.clone()
+}
+
+// Run dumps the state, subscribes to bspwm's events and keeps the state in sync, until the context is done
+// or the subscription fails. It blocks, and always returns a non-nil error.
+func (t *StateTracker) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sub, err := t.resync(ctx, false)
+	if err != nil {
+		return err
+	}
+
+	var resyncCh <-chan time.Time
+	if t.resyncInterval > 0 {
+		ticker := time.NewTicker(t.resyncInterval)
+		defer ticker.Stop()
+
+		resyncCh = ticker.C
+	}
+
+	// This works like ConsumeEvents, except that the state is dumped again every so often, between events.
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ev, ok := <-sub.eventCh:
+			if !ok {
+				return subscriptionEnded(ctx, sub.errCh)
+			}
+
+			t.handle(ctx, ev)
+		case err, ok := <-sub.errCh:
+			if ok {
+				return err
+			}
+
+			sub.errCh = nil
+		case <-resyncCh:
+			// The events that were already received are applied first, so that they don't count as drift.
+			t.handlePending(ctx, sub.eventCh)
+
+			next, err := t.resync(ctx, true)
+			if err != nil {
+				if ctxErr := ctx.Err(); ctxErr != nil {
+					return ctxErr
+				}
+
+				return err
+			}
+
+			sub.cancel()
+			sub = next
+		}
+	}
+}
+
+// trackedSubscription is a subscription to the tracked events, that can be ended on its own.
+type trackedSubscription struct {
+	eventCh <-chan Event
+	errCh   <-chan error
+	cancel  context.CancelFunc
+}
+
+// resync replaces the tracked state with a fresh dump, and returns a new subscription to the events that follow it.
+//
+// A dump doesn't tell which of the events received around it it reflects, so the subscription starts between
+// two dumps instead. When both dumps are the same, nothing changed in between, and the subscription's events
+// can be applied to the second one. Otherwise, it's tried again after a while, with a new subscription and dump.
+// After resyncAttempts tries, the last dump is kept anyway, and the next resync fixes what it got wrong.
+func (t *StateTracker) resync(ctx context.Context, detectDrift bool) (trackedSubscription, error) {
+	dumped, err := t.client.DumpStateContext(ctx)
+	if err != nil {
+		return trackedSubscription{}, fmt.Errorf("failed to dump state: %w", err)
+	}
+
+	// The tracked state is compared with the first dump, as it's the one that follows the tracked events.
+	t.mu.RLock()
+	tracked := t.state
+	t.mu.RUnlock()
+
+	first := dumped
+
+	var sub trackedSubscription
+	for attempt, backoff := 1, resyncBackoff; ; attempt, backoff = attempt+1, 2*backoff {
+		subCtx, cancel := context.WithCancel(ctx)
+
+		eventCh, errCh, err := t.client.SubscribeEventsContext(subCtx, trackedEvents[0], trackedEvents[1:]...)
+		if err != nil {
+			cancel()
+			return trackedSubscription{}, fmt.Errorf("failed to subscribe to events: %w", err)
+		}
+
+		sub = trackedSubscription{eventCh: eventCh, errCh: errCh, cancel: cancel}
+
+		next, err := t.client.DumpStateContext(ctx)
+		if err != nil {
+			cancel()
+			return trackedSubscription{}, fmt.Errorf("failed to dump state: %w", err)
+		}
+
+		same := sameState(dumped, next)
+		dumped = next
+
+		if same || attempt == resyncAttempts {
+			break
+		}
+
+		cancel()
+
+		// bspwm is busy, so it's given some time to settle before being dumped again.
+		select {
+		case <-ctx.Done():
+			return trackedSubscription{}, ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+
+	t.mu.Lock()
+	t.state = dumped
+	t.mu.Unlock()
+
+	drifted := detectDrift && !sameState(tracked, first)
+	if drifted {
+		t.callbacksMu.Lock()
+		callbacks := t.onDrift
+		t.callbacksMu.Unlock()
+
+		for _, fn := range callbacks {
+			fn(tracked.clone(), first.clone())
+		}
+	}
+
+	if !detectDrift || drifted || !sameState(first, dumped) {
+		t.notifyChange(dumped.clone())
+	}
+
+	return sub, nil
+}
+
+// handle applies the event to the tracked state, and notifies the change.
+func (t *StateTracker) handle(ctx context.Context, ev Event) {
+	// Queries go out before taking the lock, so that snapshots aren't held up by bspwm.
+	q := t.query(ctx, ev)
+
+	t.mu.Lock()
+	t.apply(ev, q)
+	st := t.state.clone()
+	t.mu.Unlock()
+
+	t.notifyChange(st)
+}
+
+// handlePending handles the events that are ready to be received, without waiting for more.
+func (t *StateTracker) handlePending(ctx context.Context, eventCh <-chan Event) {
+	for {
+		select {
+		case ev, ok := <-eventCh:
+			if !ok {
+				return
+			}
+
+			t.handle(ctx, ev)
+		default:
+			return
+		}
+	}
+}
+
+// sameState returns true if both states would be dumped the same by bspwm.
+// Comparing them as they're encoded ignores the differences that don't matter, like nil and empty slices.
+func sameState(a, b State) bool {
+	aa, err := json.Marshal(a.normalize())
+	if err != nil {
+		return false
+	}
+
+	bb, err := json.Marshal(b.normalize())
+	if err != nil {
+		return false
+	}
+
+	return bytes.Equal(aa, bb)
+}
+
+func (t *StateTracker) notifyChange(st State) {
+	t.callbacksMu.Lock()
+	callbacks := t.onChange
+	t.callbacksMu.Unlock()
+
+	for _, fn := range callbacks {
+		fn(st)
+	}
+}
+
+// queried holds what bspwm was queried for, because the event doesn't carry enough to be applied.
+type queried struct {
+	monitor *Monitor
+	desktop *Desktop
+}
+
+// query queries bspwm for what the event needs to be applied. Failures are logged, and leave the result empty.
+func (t *StateTracker) query(ctx context.Context, ev Event) queried {
+	var (
+		q   queried
+		err error
+	)
+
+	switch p := ev.Payload.(type) {
+	case EventMonitorAdd:
+		var m Monitor
+		if m, err = t.client.MonitorTreeContext(ctx, ByID(p.MonitorID)); err == nil {
+			q.monitor = &m
+		}
+	case EventDesktopAdd:
+		q.desktop, err = t.queryDesktop(ctx, p.DesktopID)
+	case EventNodeAdd:
+		q.desktop, err = t.queryDesktop(ctx, p.DesktopID)
+	case EventNodeTransfer:
+		// The source node is removed from its desktop, so it's also correct to refresh it when it's the same.
+		q.desktop, err = t.queryDesktop(ctx, p.DestinationDesktopID)
+	}
+
+	if err != nil {
+		t.logWarning(ev.Type, err)
+	}
+
+	return q
+}
+
+// apply updates the tracked state with the event, and what was queried for it. The caller must hold the lock.
+func (t *StateTracker) apply(ev Event, q queried) {
+	s := &t.state
+
+	switch p := ev.Payload.(type) {
+	case EventMonitorAdd:
+		m := Monitor{ID: p.MonitorID, Name: p.MonitorName, Rectangle: p.MonitorGeometry}
+		if q.monitor != nil {
+			m = *q.monitor
+		}
+
+		s.Monitors = append(s.Monitors, m)
+	case EventMonitorRename:
+		if m := s.FindMonitor(p.MonitorID); m != nil {
+			m.Name = p.MonitorNewName
+		}
+	case EventMonitorRemove:
+		for i := range s.Monitors {
+			if s.Monitors[i].ID == p.MonitorID {
+				s.Monitors = append(s.Monitors[:i], s.Monitors[i+1:]...)
+				break
+			}
+		}
+	case EventMonitorSwap:
+		src, dst := s.FindMonitor(p.SourceMonitorID), s.FindMonitor(p.DestinationMonitorID)
+		if src == nil || dst == nil {
+			return
+		}
+
+		src.Desktops, dst.Desktops = dst.Desktops, src.Desktops
+		src.FocusedDesktopID, dst.FocusedDesktopID = dst.FocusedDesktopID, src.FocusedDesktopID
+	case EventMonitorFocus:
+		s.FocusedMonitorID = p.MonitorID
+	case EventMonitorGeometry:
+		if m := s.FindMonitor(p.MonitorID); m != nil {
+			m.Rectangle = p.MonitorGeometry
+		}
+	case EventDesktopAdd:
+		m := s.FindMonitor(p.MonitorID)
+		if m == nil {
+			return
+		}
+
+		d := Desktop{ID: p.DesktopID, Name: p.DesktopName, Layout: LayoutTypeTiled, UserLayout: LayoutTypeTiled}
+		if q.desktop != nil {
+			d = *q.desktop
+		}
+
+		m.Desktops = append(m.Desktops, d)
+	case EventDesktopRename:
+		if _, d := s.desktop(p.DesktopID); d != nil {
+			d.Name = p.DesktopNewName
+		}
+	case EventDesktopRemove:
+		m := s.FindMonitor(p.MonitorID)
+		if m == nil {
+			return
+		}
+
+		m.removeDesktop(p.DesktopID)
+	case EventDesktopSwap:
+		srcMonitor, src := s.desktop(p.SourceDesktopID)
+		dstMonitor, dst := s.desktop(p.DestinationDesktopID)
+		if src == nil || dst == nil {
+			return
+		}
+
+		*src, *dst = *dst, *src
+
+		if srcMonitor != dstMonitor {
+			if srcMonitor.FocusedDesktopID == p.SourceDesktopID {
+				srcMonitor.FocusedDesktopID = p.DestinationDesktopID
+			}
+
+			if dstMonitor.FocusedDesktopID == p.DestinationDesktopID {
+				dstMonitor.FocusedDesktopID = p.SourceDesktopID
+			}
+		}
+	case EventDesktopTransfer:
+		srcMonitor, d := s.desktop(p.SourceDesktopID)
+		dstMonitor := s.FindMonitor(p.DestinationMonitorID)
+		if d == nil || dstMonitor == nil {
+			return
+		}
+
+		desktop := *d
+		srcMonitor.removeDesktop(p.SourceDesktopID)
+		dstMonitor.Desktops = append(dstMonitor.Desktops, desktop)
+	case EventDesktopFocus:
+		if m := s.FindMonitor(p.MonitorID); m != nil {
+			m.FocusedDesktopID = p.DesktopID
+		}
+
+		s.FocusedMonitorID = p.MonitorID
+	case EventDesktopActivate:
+		if m := s.FindMonitor(p.MonitorID); m != nil {
+			m.FocusedDesktopID = p.DesktopID
+		}
+	case EventDesktopLayout:
+		if _, d := s.desktop(p.DesktopID); d != nil {
+			d.Layout = p.DesktopLayout
+		}
+	case EventNodeAdd:
+		s.replaceDesktop(q.desktop)
+
+		if _, d := s.desktop(p.DesktopID); d != nil {
+			if n := d.Root.find(p.NodeID); n != nil && n.Client != nil {
+				s.ClientsCount++
+				s.StackedNodesList = append(s.StackedNodesList, p.NodeID)
+			}
+		}
+	case EventNodeRemove:
+		_, d := s.desktop(p.DesktopID)
+		if d == nil {
+			return
+		}
+
+		removed := d.removeNode(p.NodeID)
+		if removed == nil {
+			return
+		}
+
+		for _, leaf := range removed.LeafNodes() {
+			s.ClientsCount--
+			s.forgetNode(leaf.ID)
+		}
+	case EventNodeSwap:
+		_, srcDesktop := s.desktop(p.SourceDesktopID)
+		_, dstDesktop := s.desktop(p.DestinationDesktopID)
+		if srcDesktop == nil || dstDesktop == nil {
+			return
+		}
+
+		src, dst := srcDesktop.Root.find(p.SourceNodeID), dstDesktop.Root.find(p.DestinationNodeID)
+		if src == nil || dst == nil {
+			return
+		}
+
+		*src, *dst = *dst, *src
+	case EventNodeTransfer:
+		if _, d := s.desktop(p.SourceDesktopID); d != nil {
+			if removed := d.removeNode(p.SourceNodeID); removed != nil {
+				for _, leaf := range removed.LeafNodes() {
+					s.moveNodeHistory(leaf.ID, p.DestinationMonitorID, p.DestinationDesktopID)
+				}
+			}
+		}
+
+		s.replaceDesktop(q.desktop)
+	case EventNodeFocus:
+		if _, d := s.desktop(p.DesktopID); d != nil {
+			d.FocusedNodeID = p.NodeID
+		}
+
+		if m := s.FindMonitor(p.MonitorID); m != nil {
+			m.FocusedDesktopID = p.DesktopID
+		}
+
+		s.FocusedMonitorID = p.MonitorID
+		s.addFocusHistory(StateFocusHistoryEntry{MonitorID: p.MonitorID, DesktopID: p.DesktopID, NodeID: p.NodeID})
+	case EventNodeActivate:
+		if _, d := s.desktop(p.DesktopID); d != nil {
+			d.FocusedNodeID = p.NodeID
+		}
+	case EventNodeStack:
+		s.restack(p.Node1ID, p.RelativePosition, p.Node2ID)
+	case EventNodeGeometry:
+		n := s.node(p.DesktopID, p.NodeID)
+		if n == nil || n.Client == nil {
+			return
+		}
+
+		switch n.Client.State {
+		case StateTypeTiled, StateTypePseudoTiled:
+			n.Client.TiledRectangle = p.NodeGeometry
+		case StateTypeFloating:
+			n.Client.FloatingRectangle = p.NodeGeometry
+		}
+	case EventNodeState:
+		n := s.node(p.DesktopID, p.NodeID)
+		if n == nil || n.Client == nil || !p.WasEnabled {
+			return
+		}
+
+		n.Client.LastState = n.Client.State
+		n.Client.State = p.State
+	case EventNodeFlag:
+		n := s.node(p.DesktopID, p.NodeID)
+		if n == nil {
+			return
+		}
+
+		switch p.Flag {
+		case FlagTypeHidden:
+			n.Hidden = p.WasEnabled
+		case FlagTypeSticky:
+			n.Sticky = p.WasEnabled
+		case FlagTypePrivate:
+			n.Private = p.WasEnabled
+		case FlagTypeLocked:
+			n.Locked = p.WasEnabled
+		case FlagTypeMarked:
+			n.Marked = p.WasEnabled
+		case FlagTypeUrgent:
+			if n.Client != nil {
+				n.Client.Urgent = p.WasEnabled
+			}
+		}
+	case EventNodeLayer:
+		n := s.node(p.DesktopID, p.NodeID)
+		if n == nil || n.Client == nil {
+			return
+		}
+
+		n.Client.LastLayer = n.Client.Layer
+		n.Client.Layer = p.Layer
+	case EventNodeSplit:
+		if n := s.node(p.DesktopID, p.NodeID); n != nil {
+			n.SplitType = p.SplitType
+			n.SplitRatio = p.SplitRatio
+		}
+	}
+}
+
+func (t *StateTracker) queryDesktop(ctx context.Context, id ID) (*Desktop, error) {
+	d, err := t.client.DesktopTreeContext(ctx, ByID(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query desktop %s: %w", ByID(id), err)
+	}
+
+	return &d, nil
+}
+
+func (t *StateTracker) logWarning(ev EventType, err error) {
+	if l := t.logger; l != nil {
+		l.Warn(fmt.Sprintf(`state tracker: "%s" event - %v`, ev, err))
+	}
+}
+
+func (s *State) desktop(id ID) (*Monitor, *Desktop) {
+	for i := range s.Monitors {
+		m := &s.Monitors[i]
+		for j := range m.Desktops {
+			if m.Desktops[j].ID == id {
+				return m, &m.Desktops[j]
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// replaceDesktop replaces the tracked desktop with a freshly queried one, if both exist.
+func (s *State) replaceDesktop(fresh *Desktop) {
+	if fresh == nil {
+		return
+	}
+
+	if _, d := s.desktop(fresh.ID); d != nil {
+		*d = *fresh
+	}
+}
+
+func (s *State) node(desktopID, nodeID ID) *Node {
+	_, d := s.desktop(desktopID)
+	if d == nil {
+		return nil
+	}
+
+	return d.Root.find(nodeID)
+}
+
+// addFocusHistory appends the entry to the focus history, dropping the node's older entries,
+// just like bspwm does.
+func (s *State) addFocusHistory(entry StateFocusHistoryEntry) {
+	history := s.FocusHistory[:0]
+	for _, e := range s.FocusHistory {
+		if e.NodeID == entry.NodeID && e.DesktopID == entry.DesktopID {
+			continue
+		}
+
+		history = append(history, e)
+	}
+
+	s.FocusHistory = append(history, entry)
+}
+
+// moveNodeHistory updates the node's focus history entries, after it moved to another desktop.
+func (s *State) moveNodeHistory(nodeID, monitorID, desktopID ID) {
+	for i := range s.FocusHistory {
+		if s.FocusHistory[i].NodeID == nodeID {
+			s.FocusHistory[i].MonitorID = monitorID
+			s.FocusHistory[i].DesktopID = desktopID
+		}
+	}
+}
+
+// forgetNode removes every reference to a node that no longer exists.
+func (s *State) forgetNode(id ID) {
+	history := s.FocusHistory[:0]
+	for _, e := range s.FocusHistory {
+		if e.NodeID != id {
+			history = append(history, e)
+		}
+	}
+	s.FocusHistory = history
+
+	s.StackedNodesList = removeID(s.StackedNodesList, id)
+}
+
+// restack moves the first node right above, or right below, the second one, in the stacking list.
+func (s *State) restack(id ID, pos RelativePositionType, relativeTo ID) {
+	list := removeID(s.StackedNodesList, id)
+
+	for i, other := range list {
+		if other != relativeTo {
+			continue
+		}
+
+		// The stacking list goes from the bottom to the top.
+		if pos == RelativePositionTypeAbove {
+			i++
+		}
+
+		list = append(list[:i], append([]ID{id}, list[i:]...)...)
+		break
+	}
+
+	s.StackedNodesList = list
+}
+
+func (m *Monitor) removeDesktop(id ID) {
+	for i := range m.Desktops {
+		if m.Desktops[i].ID == id {
+			m.Desktops = append(m.Desktops[:i], m.Desktops[i+1:]...)
+			break
+		}
+	}
+
+	if m.FocusedDesktopID == id {
+		m.FocusedDesktopID = NilID
+	}
+}
+
+// removeNode removes the subtree rooted at the node from the desktop, and returns it.
+// The node's sibling takes the place of their parent, just like in bspwm.
+func (d *Desktop) removeNode(id ID) *Node {
+	var removed Node
+
+	switch {
+	case d.Root.ID == id:
+		removed = d.Root
+		d.Root = Node{}
+	default:
+		parent := d.Root.Parent(id)
+		if parent == nil {
+			return nil
+		}
+
+		sibling := parent.SecondChild
+		removed = *parent.FirstChild
+		if parent.SecondChild.ID == id {
+			sibling = parent.FirstChild
+			removed = *parent.SecondChild
+		}
+
+		*parent = *sibling
+	}
+
+	if removed.find(d.FocusedNodeID) != nil {
+		d.FocusedNodeID = NilID
+	}
+
+	return &removed
+}
+
+func (s State) clone() State {
+	c := s
+
+	c.Monitors = nil
+	for _, m := range s.Monitors {
+		c.Monitors = append(c.Monitors, m.clone())
+	}
+
+	c.FocusHistory = append([]StateFocusHistoryEntry(nil), s.FocusHistory...)
+	c.StackedNodesList = append([]ID(nil), s.StackedNodesList...)
+
+	return c
+}
+
+// normalize returns a copy of the state, with empty slices where the given one has nil ones.
+func (s State) normalize() State {
+	c := s.clone()
+
+	if c.Monitors == nil {
+		c.Monitors = []Monitor{}
+	}
+
+	for i := range c.Monitors {
+		if c.Monitors[i].Desktops == nil {
+			c.Monitors[i].Desktops = []Desktop{}
+		}
+	}
+
+	if c.FocusHistory == nil {
+		c.FocusHistory = []StateFocusHistoryEntry{}
+	}
+
+	if c.StackedNodesList == nil {
+		c.StackedNodesList = []ID{}
+	}
+
+	return c
+}
+
+func (m Monitor) clone() Monitor {
+	c := m
+
+	c.Desktops = nil
+	for _, d := range m.Desktops {
+		c.Desktops = append(c.Desktops, d.clone())
+	}
+
+	return c
+}
+
+func (d Desktop) clone() Desktop {
+	c := d
+	c.Root = *d.Root.clone()
+
+	return c
+}
+
+func (n Node) clone() *Node {
+	c := n
+
+	if n.Preselect != nil {
+		presel := *n.Preselect
+		c.Preselect = &presel
+	}
+
+	if n.Client != nil {
+		client := *n.Client
+		c.Client = &client
+	}
+
+	if n.FirstChild != nil {
+		c.FirstChild = n.FirstChild.clone()
+	}
+
+	if n.SecondChild != nil {
+		c.SecondChild = n.SecondChild.clone()
+	}
+
+	return &c
+}
+
+func removeID(ids []ID, id ID) []ID {
+	res := make([]ID, 0, len(ids))
+	for _, other := range ids {
+		if other != id {
+			res = append(res, other)
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,270 @@
+package bspc_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diogox/bspc-go"
+	"github.com/diogox/bspc-go/bspctest"
+)
+
+func TestStateTracker(t *testing.T) {
+	const (
+		monitorID = bspc.ID(0x00200002)
+		desktopID = bspc.ID(0x00200003)
+		parentID  = bspc.ID(0x00400001)
+		firstID   = bspc.ID(0x00E00003)
+		secondID  = bspc.ID(0x00E00004)
+	)
+
+	dump := bspc.State{
+		FocusedMonitorID: monitorID,
+		ClientsCount:     2,
+		Monitors: []bspc.Monitor{{
+			ID:               monitorID,
+			Name:             "eDP-1",
+			FocusedDesktopID: desktopID,
+			Desktops: []bspc.Desktop{{
+				ID:            desktopID,
+				Name:          "I",
+				Layout:        bspc.LayoutTypeTiled,
+				FocusedNodeID: firstID,
+				Root: bspc.Node{
+					ID:          parentID,
+					SplitType:   bspc.SplitTypeVertical,
+					FirstChild:  &bspc.Node{ID: firstID, Client: &bspc.NodeClient{ClassName: "Alacritty"}},
+					SecondChild: &bspc.Node{ID: secondID, Client: &bspc.NodeClient{ClassName: "firefox"}},
+				},
+			}},
+		}},
+		FocusHistory:     []bspc.StateFocusHistoryEntry{{MonitorID: monitorID, DesktopID: desktopID, NodeID: firstID}},
+		StackedNodesList: []bspc.ID{firstID, secondID},
+	}
+
+	// startTracker runs a tracker with the given resync interval, once setup is done with it, if any.
+	startTracker := func(t *testing.T, resyncInterval time.Duration, setup func(*bspctest.Server, *bspc.StateTracker)) (*bspc.StateTracker, chan bspc.State, *bspctest.Server) {
+		c, srv := newTestClient(t)
+		srv.RespondJSON("wm --dump-state", dump)
+
+		tracker := bspc.NewStateTracker(c, nil, resyncInterval)
+		if setup != nil {
+			setup(srv, tracker)
+		}
+
+		changes := make(chan bspc.State, 10)
+		tracker.OnChange(func(st bspc.State) {
+			// Changes beyond what the test waits for are dropped, instead of blocking the tracker.
+			select {
+			case changes <- st:
+			default:
+			}
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- tracker.Run(ctx) }()
+
+		srv.WaitForSubscribers(1)
+		<-changes // The initial dump.
+
+		t.Cleanup(func() {
+			cancel()
+			assert.Equal(t, context.Canceled, <-done)
+		})
+
+		return tracker, changes, srv
+	}
+
+	t.Run("should apply focus changes", func(t *testing.T) {
+		tracker, changes, srv := startTracker(t, 0, nil)
+		srv.Publish("node_focus 0x00200002 0x00200003 0x00E00004")
+
+		st := waitForChange(t, changes)
+
+		desktop := st.Monitors[0].Desktops[0]
+		assert.Equal(t, secondID, desktop.FocusedNodeID)
+		assert.Equal(t, secondID, st.FocusHistory[len(st.FocusHistory)-1].NodeID)
+		assert.Equal(t, st, tracker.Snapshot())
+	})
+
+	t.Run("should remove nodes and promote their siblings", func(t *testing.T) {
+		_, changes, srv := startTracker(t, 0, nil)
+		srv.Publish("node_remove 0x00200002 0x00200003 0x00E00003")
+
+		st := waitForChange(t, changes)
+
+		desktop := st.Monitors[0].Desktops[0]
+		assert.Equal(t, secondID, desktop.Root.ID)
+		assert.True(t, desktop.Root.IsLeaf())
+		assert.Equal(t, bspc.NilID, desktop.FocusedNodeID)
+		assert.Equal(t, 1, st.ClientsCount)
+		assert.Empty(t, st.FocusHistory)
+		assert.Equal(t, []bspc.ID{secondID}, st.StackedNodesList)
+	})
+
+	t.Run("should apply flags, states and layers", func(t *testing.T) {
+		_, changes, srv := startTracker(t, 0, nil)
+		srv.Publish(
+			"node_flag 0x00200002 0x00200003 0x00E00003 sticky on",
+			"node_state 0x00200002 0x00200003 0x00E00004 floating on",
+			"node_layer 0x00200002 0x00200003 0x00E00004 above",
+		)
+
+		waitForChange(t, changes)
+		waitForChange(t, changes)
+		st := waitForChange(t, changes)
+
+		root := st.Monitors[0].Desktops[0].Root
+		assert.True(t, root.FirstChild.Sticky)
+		assert.Equal(t, bspc.StateTypeFloating, root.SecondChild.Client.State)
+		assert.Equal(t, bspc.LayerTypeAbove, root.SecondChild.Client.Layer)
+	})
+
+	t.Run("should not share its state with snapshots", func(t *testing.T) {
+		tracker, _, _ := startTracker(t, 0, nil)
+
+		snapshot := tracker.Snapshot()
+		snapshot.Monitors[0].Desktops[0].Root.FirstChild.Client.ClassName = "changed"
+
+		assert.Equal(t, "Alacritty", tracker.Snapshot().Monitors[0].Desktops[0].Root.FirstChild.Client.ClassName)
+	})
+
+	t.Run("should not hold snapshots up while querying bspwm", func(t *testing.T) {
+		tracker, _, srv := startTracker(t, 0, func(srv *bspctest.Server, _ *bspc.StateTracker) {
+			srv.Hang("query --tree --desktop 0x00200003")
+		})
+		srv.Publish("node_add 0x00200002 0x00200003 0x00400001 0x00E00005")
+
+		require.Eventually(t, func() bool {
+			for _, cmd := range srv.Commands() {
+				if cmd == "query --tree --desktop 0x00200003" {
+					return true
+				}
+			}
+
+			return false
+		}, 5*time.Second, time.Millisecond)
+
+		snapshot := make(chan bspc.State)
+		go func() { snapshot <- tracker.Snapshot() }()
+
+		select {
+		case st := <-snapshot:
+			assert.Equal(t, 2, st.ClientsCount)
+		case <-time.After(time.Second):
+			assert.Fail(t, "timed out waiting for a snapshot")
+		}
+	})
+
+	t.Run("should not apply the events that the dump reflects", func(t *testing.T) {
+		// This dump reflects the node's removal, unlike the first one.
+		removed := dump
+		removed.ClientsCount = 1
+
+		var dumps int32
+		_, changes, srv := startTracker(t, 0, func(srv *bspctest.Server, _ *bspc.StateTracker) {
+			srv.RespondFunc("wm --dump-state", func() string {
+				st := removed
+				switch atomic.AddInt32(&dumps, 1) {
+				case 1:
+					st = dump
+				case 2:
+					// The node is removed after the subscription starts, and before the second dump.
+					srv.WaitForSubscribers(1)
+					srv.Publish("node_remove 0x00200002 0x00200003 0x00E00003")
+				}
+
+				bb, err := json.Marshal(st)
+				require.NoError(t, err)
+
+				return string(bb)
+			})
+		})
+
+		// The state changed between the first two dumps, so it's dumped again, with a new subscription.
+		srv.WaitForSubscribers(2)
+		srv.Publish("node_focus 0x00200002 0x00200003 0x00E00004")
+
+		st := waitForChange(t, changes)
+		assert.Equal(t, 1, st.ClientsCount)
+		assert.Equal(t, secondID, st.Monitors[0].Desktops[0].FocusedNodeID)
+
+		var dumped, subscribed int
+		for _, cmd := range srv.Commands() {
+			switch strings.Fields(cmd)[0] {
+			case "wm":
+				dumped++
+			case "subscribe":
+				subscribed++
+			}
+		}
+		assert.Equal(t, 3, dumped)
+		assert.Equal(t, 2, subscribed)
+	})
+
+	t.Run("should keep the last dump, when the state keeps changing", func(t *testing.T) {
+		var dumps int32
+		tracker, _, srv := startTracker(t, 0, func(srv *bspctest.Server, _ *bspc.StateTracker) {
+			srv.RespondFunc("wm --dump-state", func() string {
+				st := dump
+				st.ClientsCount = int(atomic.AddInt32(&dumps, 1))
+
+				bb, err := json.Marshal(st)
+				require.NoError(t, err)
+
+				return string(bb)
+			})
+		})
+
+		assert.Equal(t, 6, tracker.Snapshot().ClientsCount)
+		assert.Equal(t, int32(6), atomic.LoadInt32(&dumps))
+		// Five subscriptions, one per attempt, between the six dumps.
+		assert.Eventually(t, func() bool { return len(srv.Commands()) == 11 }, time.Second, time.Millisecond)
+	})
+
+	t.Run("should not report nil and empty slices as drift", func(t *testing.T) {
+		drifted := make(chan bspc.State, 10)
+
+		withHistory := dump
+		withHistory.FocusHistory = []bspc.StateFocusHistoryEntry{}
+
+		withoutHistory := dump
+		withoutHistory.FocusHistory = nil
+
+		_, _, srv := startTracker(t, 10*time.Millisecond, func(srv *bspctest.Server, tracker *bspc.StateTracker) {
+			srv.RespondJSON("wm --dump-state", withHistory)
+			tracker.OnDrift(func(_, dumped bspc.State) { drifted <- dumped })
+		})
+		srv.RespondJSON("wm --dump-state", withoutHistory)
+
+		require.Eventually(t, func() bool {
+			var dumps int
+			for _, cmd := range srv.Commands() {
+				if cmd == "wm --dump-state" {
+					dumps++
+				}
+			}
+
+			return dumps >= 3
+		}, 5*time.Second, time.Millisecond)
+
+		assert.Empty(t, drifted)
+	})
+}
+
+func waitForChange(t *testing.T, changes chan bspc.State) bspc.State {
+	select {
+	case st := <-changes:
+		return st
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timed out waiting for a state change")
+		return bspc.State{}
+	}
+}
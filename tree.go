@@ -0,0 +1,248 @@
+package bspc
+
+import (
+	"strings"
+)
+
+// NodeLocation is where a node sits in the state: its monitor, its desktop, and the nodes above it.
+// Its pointers point into the state it was found in.
+type NodeLocation struct {
+	Monitor *Monitor
+	Desktop *Desktop
+	Node    *Node
+	// Parents go from the node's parent up to the root of its desktop.
+	Parents []*Node
+	// Path is the path of the node from the root of its desktop, as in: @/1/2
+	Path string
+}
+
+// Selector returns the selector matching the node through its path, in its desktop.
+// Unlike its ID, it keeps matching whichever node takes its place.
+func (l NodeLocation) Selector() Selector {
+	return PathOn(ByID(l.Desktop.ID), l.Path)
+}
+
+// FindMonitor returns the monitor with the given ID, or nil if there's none.
+func (s *State) FindMonitor(id ID) *Monitor {
+	for i := range s.Monitors {
+		if s.Monitors[i].ID == id {
+			return &s.Monitors[i]
+		}
+	}
+
+	return nil
+}
+
+// FindDesktop returns the desktop with the given ID, or nil if there's none.
+func (s *State) FindDesktop(id ID) *Desktop {
+	_, d := s.desktop(id)
+	return d
+}
+
+// FindNode returns the node with the given ID, in any desktop, or nil if there's none.
+func (s *State) FindNode(id ID) *Node {
+	if l, ok := s.NodeLocation(id); ok {
+		return l.Node
+	}
+
+	return nil
+}
+
+// NodeLocation returns where the node with the given ID sits, and whether it was found.
+// Empty desktops hold no node, so NilID is never found.
+func (s *State) NodeLocation(id ID) (NodeLocation, bool) {
+	if id == NilID {
+		return NodeLocation{}, false
+	}
+
+	for i := range s.Monitors {
+		m := &s.Monitors[i]
+		for j := range m.Desktops {
+			d := &m.Desktops[j]
+			if d.Root.ID == NilID {
+				continue
+			}
+
+			chain, path := d.Root.ancestry(id)
+			if chain == nil {
+				continue
+			}
+
+			parents := make([]*Node, 0, len(chain)-1)
+			for k := len(chain) - 2; k >= 0; k-- {
+				parents = append(parents, chain[k])
+			}
+
+			return NodeLocation{
+				Monitor: m,
+				Desktop: d,
+				Node:    chain[len(chain)-1],
+				Parents: parents,
+				Path:    path,
+			}, true
+		}
+	}
+
+	return NodeLocation{}, false
+}
+
+// FindDesktop returns the desktop of the monitor with the given ID, or nil if there's none.
+func (m *Monitor) FindDesktop(id ID) *Desktop {
+	for i := range m.Desktops {
+		if m.Desktops[i].ID == id {
+			return &m.Desktops[i]
+		}
+	}
+
+	return nil
+}
+
+// FindNode returns the node of the desktop with the given ID, or nil if there's none.
+func (d *Desktop) FindNode(id ID) *Node {
+	return d.Root.find(id)
+}
+
+// Walk calls fn for every node in the tree rooted at this node, this one included.
+// Parents are visited before their children in pre-order, and after them in post-order.
+// First children are always visited before second children. Walking stops as soon as fn returns false.
+func (n *Node) Walk(order WalkOrderType, fn func(node *Node) bool) {
+	n.walk(order, fn)
+}
+
+// walk returns false once fn does.
+func (n *Node) walk(order WalkOrderType, fn func(node *Node) bool) bool {
+	if order != WalkOrderTypePost && !fn(n) {
+		return false
+	}
+
+	for _, child := range []*Node{n.FirstChild, n.SecondChild} {
+		if child != nil && !child.walk(order, fn) {
+			return false
+		}
+	}
+
+	if order == WalkOrderTypePost {
+		return fn(n)
+	}
+
+	return true
+}
+
+// Find returns the first node, in pre-order, matching the predicate, in the tree rooted at this node.
+// It returns nil if there's none.
+func (n *Node) Find(predicate func(node *Node) bool) *Node {
+	var found *Node
+
+	n.Walk(WalkOrderTypePre, func(node *Node) bool {
+		if predicate(node) {
+			found = node
+			return false
+		}
+
+		return true
+	})
+
+	return found
+}
+
+// Parent returns the parent of the node with the given ID, in the tree rooted at this node.
+// It returns nil if the node isn't in the tree, or if it is this node.
+func (n *Node) Parent(id ID) *Node {
+	chain, _ := n.ancestry(id)
+	if len(chain) < 2 {
+		return nil
+	}
+
+	return chain[len(chain)-2]
+}
+
+// Sibling returns the other child of the parent of the node with the given ID, in the tree rooted at this node.
+// It returns nil if the node has no parent in the tree.
+func (n *Node) Sibling(id ID) *Node {
+	parent := n.Parent(id)
+	if parent == nil {
+		return nil
+	}
+
+	if parent.FirstChild != nil && parent.FirstChild.ID == id {
+		return parent.SecondChild
+	}
+
+	return parent.FirstChild
+}
+
+// Depth returns how far down the node with the given ID is, in the tree rooted at this node.
+// This node is at depth 0. It returns -1 if the node isn't in the tree.
+func (n *Node) Depth(id ID) int {
+	chain, _ := n.ancestry(id)
+	return len(chain) - 1
+}
+
+// Path returns the path of the node with the given ID from this node, in bspwm's notation, as in: @/1/2
+// It returns false if the node isn't in the tree.
+func (n *Node) Path(id ID) (string, bool) {
+	chain, path := n.ancestry(id)
+	return path, chain != nil
+}
+
+// FilterLeaves returns the leaves in the tree rooted at this node that match the predicate, from left to right.
+func (n Node) FilterLeaves(predicate func(leaf Node) bool) []Node {
+	leaves := make([]Node, 0)
+	for _, l := range n.LeafNodes() {
+		if predicate(l) {
+			leaves = append(leaves, l)
+		}
+	}
+
+	return leaves
+}
+
+// FloatingLeaves returns the leaves in the tree rooted at this node, whose windows are floating.
+func (n Node) FloatingLeaves() []Node {
+	return n.FilterLeaves(func(l Node) bool {
+		return l.Client.State == StateTypeFloating
+	})
+}
+
+// HiddenLeaves returns the leaves in the tree rooted at this node, that are hidden.
+func (n Node) HiddenLeaves() []Node {
+	return n.FilterLeaves(func(l Node) bool {
+		return l.Hidden
+	})
+}
+
+// find returns the node with the given ID, in the tree rooted at this node.
+func (n *Node) find(id ID) *Node {
+	return n.Find(func(node *Node) bool {
+		return node.ID == id
+	})
+}
+
+// ancestry returns the nodes from this node down to the node with the given ID, along with the latter's path.
+// It returns nil if the node isn't in the tree.
+func (n *Node) ancestry(id ID) ([]*Node, string) {
+	if n.ID == id {
+		return []*Node{n}, "@/"
+	}
+
+	for i, child := range []*Node{n.FirstChild, n.SecondChild} {
+		if child == nil {
+			continue
+		}
+
+		chain, path := child.ancestry(id)
+		if chain == nil {
+			continue
+		}
+
+		jump := "1"
+		if i == 1 {
+			jump = "2"
+		}
+
+		// The path is built bottom up, so each jump goes right after the root.
+		return append([]*Node{n}, chain...), "@/" + strings.TrimSuffix(jump+"/"+strings.TrimPrefix(path, "@/"), "/")
+	}
+
+	return nil, ""
+}
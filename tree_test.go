@@ -0,0 +1,130 @@
+package bspc_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diogox/bspc-go"
+)
+
+// newTestTree returns this tree, where only the leaves have clients:
+//
+//	     1
+//	   /   \
+//	  2     5
+//	 / \
+//	3   4
+func newTestTree() bspc.Node {
+	return bspc.Node{
+		ID: 1,
+		FirstChild: &bspc.Node{
+			ID:          2,
+			FirstChild:  &bspc.Node{ID: 3, Client: &bspc.NodeClient{State: bspc.StateTypeFloating}},
+			SecondChild: &bspc.Node{ID: 4, Hidden: true, Client: &bspc.NodeClient{State: bspc.StateTypeTiled}},
+		},
+		SecondChild: &bspc.Node{ID: 5, Client: &bspc.NodeClient{State: bspc.StateTypeFloating}},
+	}
+}
+
+func TestNode_Walk(t *testing.T) {
+	walk := func(root bspc.Node, order bspc.WalkOrderType, stopAt bspc.ID) []bspc.ID {
+		var visited []bspc.ID
+		root.Walk(order, func(n *bspc.Node) bool {
+			visited = append(visited, n.ID)
+			return n.ID != stopAt
+		})
+
+		return visited
+	}
+
+	t.Run("should visit the nodes in order", func(t *testing.T) {
+		assert.Equal(t, []bspc.ID{1, 2, 3, 4, 5}, walk(newTestTree(), bspc.WalkOrderTypePre, 0))
+		assert.Equal(t, []bspc.ID{3, 4, 2, 5, 1}, walk(newTestTree(), bspc.WalkOrderTypePost, 0))
+	})
+
+	t.Run("should stop once the callback returns false", func(t *testing.T) {
+		assert.Equal(t, []bspc.ID{1, 2, 3}, walk(newTestTree(), bspc.WalkOrderTypePre, 3))
+		assert.Equal(t, []bspc.ID{3, 4, 2}, walk(newTestTree(), bspc.WalkOrderTypePost, 2))
+	})
+}
+
+func TestNode_Navigation(t *testing.T) {
+	root := newTestTree()
+
+	t.Run("should find parents, siblings and depths", func(t *testing.T) {
+		assert.Equal(t, bspc.ID(2), root.Parent(4).ID)
+		assert.Nil(t, root.Parent(1))
+		assert.Nil(t, root.Parent(42))
+
+		assert.Equal(t, bspc.ID(3), root.Sibling(4).ID)
+		assert.Equal(t, bspc.ID(5), root.Sibling(2).ID)
+		assert.Nil(t, root.Sibling(1))
+
+		assert.Equal(t, 0, root.Depth(1))
+		assert.Equal(t, 2, root.Depth(3))
+		assert.Equal(t, -1, root.Depth(42))
+	})
+
+	t.Run("should return paths in bspwm's notation", func(t *testing.T) {
+		for id, want := range map[bspc.ID]string{1: "@/", 2: "@/1", 4: "@/1/2", 5: "@/2"} {
+			path, ok := root.Path(id)
+			require.True(t, ok)
+			assert.Equal(t, want, path, id)
+		}
+
+		_, ok := root.Path(42)
+		assert.False(t, ok)
+	})
+
+	t.Run("should find nodes and filter leaves", func(t *testing.T) {
+		found := root.Find(func(n *bspc.Node) bool { return n.Hidden })
+		assert.Equal(t, bspc.ID(4), found.ID)
+		assert.Nil(t, root.Find(func(n *bspc.Node) bool { return n.Marked }))
+
+		assert.Len(t, root.FloatingLeaves(), 2)
+		assert.Equal(t, bspc.ID(4), root.HiddenLeaves()[0].ID)
+	})
+}
+
+func TestState_NodeLocation(t *testing.T) {
+	st := bspc.State{
+		Monitors: []bspc.Monitor{
+			{ID: 10, Desktops: []bspc.Desktop{{ID: 19}, {ID: 20, Root: bspc.Node{ID: 30}}}},
+			{ID: 11, Desktops: []bspc.Desktop{{ID: 21, Root: newTestTree()}}},
+		},
+	}
+
+	t.Run("should locate nodes in any desktop", func(t *testing.T) {
+		l, ok := st.NodeLocation(4)
+		require.True(t, ok)
+
+		assert.Equal(t, bspc.ID(11), l.Monitor.ID)
+		assert.Equal(t, bspc.ID(21), l.Desktop.ID)
+		assert.Equal(t, bspc.ID(4), l.Node.ID)
+		assert.Equal(t, []bspc.ID{2, 1}, []bspc.ID{l.Parents[0].ID, l.Parents[1].ID})
+		assert.Equal(t, "@0x00000015:/1/2", l.Selector().String())
+
+		_, ok = st.NodeLocation(42)
+		assert.False(t, ok)
+	})
+
+	t.Run("should not locate nodes in empty desktops", func(t *testing.T) {
+		_, ok := st.NodeLocation(bspc.NilID)
+		assert.False(t, ok)
+
+		l, ok := st.NodeLocation(30)
+		require.True(t, ok)
+		assert.Equal(t, bspc.ID(20), l.Desktop.ID)
+	})
+
+	t.Run("should point into the state", func(t *testing.T) {
+		st.FindNode(5).Marked = true
+		assert.True(t, st.Monitors[1].Desktops[0].Root.SecondChild.Marked)
+
+		assert.Equal(t, bspc.ID(30), st.FindDesktop(20).Root.ID)
+		assert.Equal(t, bspc.ID(11), st.FindMonitor(11).ID)
+		assert.Nil(t, st.FindMonitor(20))
+	})
+}
@@ -1,15 +1,27 @@
 package bspc
 
 type (
-	LayoutType             string
-	SplitType              string
-	DirectionType          string
-	StateType              string
-	FlagType               string
-	RelativePositionType   string
-	LayerType              string
-	PointerActionType      string
-	PointerActionStateType string
+	LayoutType               string
+	SplitType                string
+	DirectionType            string
+	StateType                string
+	FlagType                 string
+	RelativePositionType     string
+	LayerType                string
+	PointerActionType        string
+	PointerActionStateType   string
+	HandleType               string
+	CirculateDirType         string
+	RotationType             string
+	CycleDirType             string
+	ToggleType               string
+	AutomaticSchemeType      string
+	PolarityType             string
+	TightnessType            string
+	PointerModifierType      string
+	PointerActionSettingType string
+	ClickToFocusType         string
+	WalkOrderType            string
 )
 
 const (
@@ -49,6 +61,64 @@ const (
 
 	PointerActionStateTypeBegin PointerActionStateType = "begin"
 	PointerActionStateTypeEnd   PointerActionStateType = "end"
+
+	HandleTypeTop         HandleType = "top"
+	HandleTypeLeft        HandleType = "left"
+	HandleTypeBottom      HandleType = "bottom"
+	HandleTypeRight       HandleType = "right"
+	HandleTypeTopLeft     HandleType = "top_left"
+	HandleTypeTopRight    HandleType = "top_right"
+	HandleTypeBottomRight HandleType = "bottom_right"
+	HandleTypeBottomLeft  HandleType = "bottom_left"
+
+	CirculateDirTypeForward  CirculateDirType = "forward"
+	CirculateDirTypeBackward CirculateDirType = "backward"
+
+	RotationType90  RotationType = "90"
+	RotationType180 RotationType = "180"
+	RotationType270 RotationType = "270"
+
+	CycleDirTypeNext CycleDirType = "next"
+	CycleDirTypePrev CycleDirType = "prev"
+
+	ToggleTypeOn  ToggleType = "on"
+	ToggleTypeOff ToggleType = "off"
+
+	AutomaticSchemeTypeLongestSide AutomaticSchemeType = "longest_side"
+	AutomaticSchemeTypeAlternate   AutomaticSchemeType = "alternate"
+	AutomaticSchemeTypeSpiral      AutomaticSchemeType = "spiral"
+
+	PolarityTypeFirstChild  PolarityType = "first_child"
+	PolarityTypeSecondChild PolarityType = "second_child"
+
+	TightnessTypeHigh TightnessType = "high"
+	TightnessTypeLow  TightnessType = "low"
+
+	PointerModifierTypeShift   PointerModifierType = "shift"
+	PointerModifierTypeControl PointerModifierType = "control"
+	PointerModifierTypeLock    PointerModifierType = "lock"
+	PointerModifierTypeMod1    PointerModifierType = "mod1"
+	PointerModifierTypeMod2    PointerModifierType = "mod2"
+	PointerModifierTypeMod3    PointerModifierType = "mod3"
+	PointerModifierTypeMod4    PointerModifierType = "mod4"
+	PointerModifierTypeMod5    PointerModifierType = "mod5"
+
+	// The actions the pointer_action settings can bind a button to. Unlike PointerActionType, which
+	// pointer_action events report, these include focusing and doing nothing.
+	PointerActionSettingTypeMove         PointerActionSettingType = "move"
+	PointerActionSettingTypeResizeSide   PointerActionSettingType = "resize_side"
+	PointerActionSettingTypeResizeCorner PointerActionSettingType = "resize_corner"
+	PointerActionSettingTypeFocus        PointerActionSettingType = "focus"
+	PointerActionSettingTypeNone         PointerActionSettingType = "none"
+
+	ClickToFocusTypeAny     ClickToFocusType = "any"
+	ClickToFocusTypeButton1 ClickToFocusType = "button1"
+	ClickToFocusTypeButton2 ClickToFocusType = "button2"
+	ClickToFocusTypeButton3 ClickToFocusType = "button3"
+	ClickToFocusTypeNone    ClickToFocusType = "none"
+
+	WalkOrderTypePre  WalkOrderType = "pre"
+	WalkOrderTypePost WalkOrderType = "post"
 )
 
 func (lt LayoutType) IsValid() bool {
@@ -105,3 +175,83 @@ func (past PointerActionStateType) IsValid() bool {
 	return past == PointerActionStateTypeBegin ||
 		past == PointerActionStateTypeEnd
 }
+
+func (ht HandleType) IsValid() bool {
+	return ht == HandleTypeTop ||
+		ht == HandleTypeLeft ||
+		ht == HandleTypeBottom ||
+		ht == HandleTypeRight ||
+		ht == HandleTypeTopLeft ||
+		ht == HandleTypeTopRight ||
+		ht == HandleTypeBottomRight ||
+		ht == HandleTypeBottomLeft
+}
+
+func (cdt CirculateDirType) IsValid() bool {
+	return cdt == CirculateDirTypeForward ||
+		cdt == CirculateDirTypeBackward
+}
+
+func (rt RotationType) IsValid() bool {
+	return rt == RotationType90 ||
+		rt == RotationType180 ||
+		rt == RotationType270
+}
+
+func (cdt CycleDirType) IsValid() bool {
+	return cdt == CycleDirTypeNext ||
+		cdt == CycleDirTypePrev
+}
+
+func (tt ToggleType) IsValid() bool {
+	return tt == ToggleTypeOn ||
+		tt == ToggleTypeOff
+}
+
+func (ast AutomaticSchemeType) IsValid() bool {
+	return ast == AutomaticSchemeTypeLongestSide ||
+		ast == AutomaticSchemeTypeAlternate ||
+		ast == AutomaticSchemeTypeSpiral
+}
+
+func (pt PolarityType) IsValid() bool {
+	return pt == PolarityTypeFirstChild ||
+		pt == PolarityTypeSecondChild
+}
+
+func (tt TightnessType) IsValid() bool {
+	return tt == TightnessTypeHigh ||
+		tt == TightnessTypeLow
+}
+
+func (pmt PointerModifierType) IsValid() bool {
+	return pmt == PointerModifierTypeShift ||
+		pmt == PointerModifierTypeControl ||
+		pmt == PointerModifierTypeLock ||
+		pmt == PointerModifierTypeMod1 ||
+		pmt == PointerModifierTypeMod2 ||
+		pmt == PointerModifierTypeMod3 ||
+		pmt == PointerModifierTypeMod4 ||
+		pmt == PointerModifierTypeMod5
+}
+
+func (past PointerActionSettingType) IsValid() bool {
+	return past == PointerActionSettingTypeMove ||
+		past == PointerActionSettingTypeResizeSide ||
+		past == PointerActionSettingTypeResizeCorner ||
+		past == PointerActionSettingTypeFocus ||
+		past == PointerActionSettingTypeNone
+}
+
+func (ctft ClickToFocusType) IsValid() bool {
+	return ctft == ClickToFocusTypeAny ||
+		ctft == ClickToFocusTypeButton1 ||
+		ctft == ClickToFocusTypeButton2 ||
+		ctft == ClickToFocusTypeButton3 ||
+		ctft == ClickToFocusTypeNone
+}
+
+func (wot WalkOrderType) IsValid() bool {
+	return wot == WalkOrderTypePre ||
+		wot == WalkOrderTypePost
+}